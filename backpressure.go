@@ -0,0 +1,384 @@
+package gotiktoklive
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// BackpressurePolicy decides what happens to new events when Live.Events is full.
+type BackpressurePolicy int
+
+const (
+	// DropOldest discards the oldest event in the channel to make room. This is the default.
+	DropOldest BackpressurePolicy = iota
+	// DropNewest discards the new event.
+	DropNewest
+	// Block waits until there is room or the live is closed. While waiting no new messages are read from the websocket.
+	Block
+	// SpillToDisk writes events that do not fit to a temporary file and feeds them back in order once there is
+	// room. No events are dropped unless the file cannot be written.
+	SpillToDisk
+)
+
+func (p BackpressurePolicy) String() string {
+	switch p {
+	case DropOldest:
+		return "drop oldest"
+	case DropNewest:
+		return "drop newest"
+	case Block:
+		return "block"
+	case SpillToDisk:
+		return "spill to disk"
+	}
+	return fmt.Sprintf("BackpressurePolicy(%d)", int(p))
+}
+
+// DroppedEvents returns how many events were dropped from the Events channel since the live started.
+func (l *Live) DroppedEvents() uint64 {
+	return l.dropped.Load()
+}
+
+// send puts an event on the Events channel according to the backpressure policy.
+func (l *Live) send(e Event) {
+	switch l.t.backpressure {
+	case DropNewest:
+		select {
+		case l.Events <- e:
+		default:
+			l.drop(e)
+		}
+	case Block:
+		// Only Close gives up on the event. The live may already be done while its last events are sent.
+		select {
+		case l.Events <- e:
+		case <-l.closed:
+			l.drop(e)
+		}
+	case SpillToDisk:
+		if l.spill != nil {
+			if l.spill.pending() == 0 {
+				select {
+				case l.Events <- e:
+					l.reportDropped()
+					return
+				default:
+				}
+			}
+			if err := l.spill.push(e); err != nil {
+				l.t.errHandler(fmt.Errorf("cannot spill %T to disk: %w", e, err))
+				l.drop(e)
+			}
+			return
+		}
+		// Spilling could not be set up, fall back to the default.
+		fallthrough
+	default:
+		if len(l.Events) == l.chanSize {
+			select {
+			case old := <-l.Events:
+				l.drop(old)
+			default:
+			}
+		}
+		l.Events <- e
+	}
+	l.reportDropped()
+}
+
+func (l *Live) drop(e Event) {
+	l.dropped.Add(1)
+	l.droppedSinceReport++
+	if _, ok := e.(GiftEvent); ok {
+		l.droppedGiftsSinceReport++
+	}
+}
+
+// reportDropped sends a DroppedEventsEvent if events were dropped since the last one and there is room for it.
+func (l *Live) reportDropped() {
+	if l.droppedSinceReport == 0 {
+		return
+	}
+	select {
+	case l.Events <- DroppedEventsEvent{
+		Dropped:      l.droppedSinceReport,
+		DroppedGifts: l.droppedGiftsSinceReport,
+		Total:        l.dropped.Load(),
+		Policy:       l.t.backpressure,
+		eventMeta:    eventMeta{created: time.Now()},
+	}:
+		l.droppedSinceReport = 0
+		l.droppedGiftsSinceReport = 0
+	default:
+	}
+}
+
+// startSpill creates the spill file and starts feeding spilled events back into the Events channel.
+func (l *Live) startSpill() error {
+	spill, err := newEventSpill(l.t.spillDir)
+	if err != nil {
+		return err
+	}
+	l.spill = spill
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		defer spill.close()
+		for {
+			e, err := spill.next(l.closed)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				l.t.errHandler(fmt.Errorf("cannot read spilled event: %w", err))
+				l.emitMu.Lock()
+				l.dropped.Add(1)
+				l.droppedSinceReport++
+				l.emitMu.Unlock()
+				spill.ack()
+				continue
+			}
+			select {
+			case l.Events <- e:
+				spill.ack()
+			case <-l.closed:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// waitSpillDrained blocks until all spilled events have been delivered or the live is closed.
+func (l *Live) waitSpillDrained() {
+	if l.spill == nil {
+		return
+	}
+	select {
+	case <-l.spill.drained():
+	case <-l.closed:
+	}
+}
+
+// eventSpill is an on disk FIFO of events. Events are written as JSON lines to a file that is truncated whenever all
+// events have been read back.
+type eventSpill struct {
+	mu      sync.Mutex
+	w       *os.File
+	r       *os.File
+	br      *bufio.Reader
+	count   int
+	notify  chan struct{}
+	isEmpty chan struct{}
+}
+
+type spilledEvent struct {
+	Type  string          `json:"type"`
+	State spillState      `json:"state"`
+	Event json.RawMessage `json:"event"`
+}
+
+func newEventSpill(dir string) (*eventSpill, error) {
+	w, err := os.CreateTemp(dir, "gotiktoklive-events-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	r, err := os.Open(w.Name())
+	if err != nil {
+		_ = w.Close()
+		_ = os.Remove(w.Name())
+		return nil, err
+	}
+	isEmpty := make(chan struct{})
+	close(isEmpty)
+	return &eventSpill{
+		w:       w,
+		r:       r,
+		br:      bufio.NewReader(r),
+		notify:  make(chan struct{}, 1),
+		isEmpty: isEmpty,
+	}, nil
+}
+
+func (s *eventSpill) push(e Event) error {
+	name, err := spillTypeName(e)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(spilledEvent{
+		Type:  name,
+		State: e.(spillable).spillState(),
+		Event: raw,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if s.count == 0 {
+		s.isEmpty = make(chan struct{})
+	}
+	s.count++
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// next blocks until a spilled event is available and decodes it. It returns io.EOF once stop is closed.
+func (s *eventSpill) next(stop <-chan struct{}) (Event, error) {
+	for {
+		s.mu.Lock()
+		if s.count > 0 {
+			line, err := s.br.ReadBytes('\n')
+			s.mu.Unlock()
+			if err != nil {
+				return nil, err
+			}
+			return decodeSpilledEvent(line)
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.notify:
+		case <-stop:
+			return nil, io.EOF
+		}
+	}
+}
+
+// ack marks the event returned by next as delivered.
+func (s *eventSpill) ack() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count--
+	if s.count > 0 {
+		return
+	}
+	// Everything was read back, start over with an empty file.
+	if err := s.w.Truncate(0); err == nil {
+		if _, err := s.w.Seek(0, io.SeekStart); err == nil {
+			if _, err := s.r.Seek(0, io.SeekStart); err == nil {
+				s.br.Reset(s.r)
+			}
+		}
+	}
+	close(s.isEmpty)
+}
+
+func (s *eventSpill) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+func (s *eventSpill) drained() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isEmpty
+}
+
+func (s *eventSpill) close() {
+	_ = s.r.Close()
+	_ = s.w.Close()
+	_ = os.Remove(s.w.Name())
+}
+
+// spillable is implemented by the events that can be spilled to disk. JSON only keeps the exported fields of an
+// event, spillState returns the unexported state, including that of nested values, to spill along with them.
+type spillable interface {
+	Event
+	spillState() spillState
+}
+
+// spillRestorer is implemented by the pointer to a spillable event, restoreSpill puts the spilled state back.
+type spillRestorer interface {
+	restoreSpill(s spillState)
+}
+
+// spillState is the unexported state of a spilled event.
+type spillState struct {
+	History bool      `json:"history,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// eventMeta is embedded in the events to hold their unexported state, which makes them spillable.
+type eventMeta struct {
+	isHistory bool
+	created   time.Time
+}
+
+func (e eventMeta) spillState() spillState {
+	return spillState{History: e.isHistory, Created: e.created}
+}
+
+func (e *eventMeta) restoreSpill(state spillState) {
+	e.isHistory = state.History
+	e.created = state.Created
+}
+
+// spillTypes maps the type names in spill files to the event types. Types are added as their events are spilled,
+// so every type read back was checked by spillTypeName.
+var spillTypes sync.Map
+
+// spillTypeName returns the name the type of the event is spilled under, or an error if the event cannot be spilled
+// without losing state.
+func spillTypeName(e Event) (string, error) {
+	t := reflect.TypeOf(e)
+	name := t.String()
+	if _, ok := spillTypes.Load(name); ok {
+		return name, nil
+	}
+	restorer := t
+	if t.Kind() != reflect.Pointer {
+		restorer = reflect.PointerTo(t)
+	}
+	if _, ok := e.(spillable); !ok || !restorer.Implements(reflect.TypeFor[spillRestorer]()) {
+		return "", fmt.Errorf("event type %s does not implement spillState and restoreSpill", name)
+	}
+	spillTypes.Store(name, t)
+	return name, nil
+}
+
+func decodeSpilledEvent(line []byte) (Event, error) {
+	var s spilledEvent
+	if err := json.Unmarshal(line, &s); err != nil {
+		return nil, err
+	}
+	v, ok := spillTypes.Load(s.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown spilled event type %s", s.Type)
+	}
+	t := v.(reflect.Type)
+	// Decode into a pointer to the event, which restoreSpill is called on. Events sent as pointers, such as
+	// DisconnectEvent, are that pointer.
+	ptr := reflect.New(t)
+	if t.Kind() == reflect.Pointer {
+		ptr.Elem().Set(reflect.New(t.Elem()))
+		ptr = ptr.Elem()
+	}
+	if err := json.Unmarshal(s.Event, ptr.Interface()); err != nil {
+		return nil, err
+	}
+	ptr.Interface().(spillRestorer).restoreSpill(s.State)
+	if t.Kind() == reflect.Pointer {
+		return ptr.Interface().(Event), nil
+	}
+	return ptr.Elem().Interface().(Event), nil
+}
@@ -0,0 +1,206 @@
+package gotiktoklive
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackpressureDropNewest(t *testing.T) {
+	tiktok := newTestTikTok(t)
+	tiktok.eventsChanSize = 2
+	tiktok.backpressure = DropNewest
+	live := newTestLive(t, tiktok)
+
+	live.emit(ChatEvent{Comment: "a"})
+	live.emit(ChatEvent{Comment: "b"})
+	live.emit(GiftEvent{Name: "Rose"})
+	live.emit(ChatEvent{Comment: "c"})
+
+	assert.Equal(t, "a", (<-live.Events).(ChatEvent).Comment)
+	assert.Equal(t, "b", (<-live.Events).(ChatEvent).Comment)
+	assert.Equal(t, uint64(2), live.DroppedEvents())
+
+	live.emit(ChatEvent{Comment: "d"})
+	assert.Equal(t, "d", (<-live.Events).(ChatEvent).Comment)
+	dropped, ok := (<-live.Events).(DroppedEventsEvent)
+	if assert.True(t, ok) {
+		assert.Equal(t, 2, dropped.Dropped)
+		assert.Equal(t, 1, dropped.DroppedGifts)
+	}
+}
+
+func TestBackpressureSpillToDisk(t *testing.T) {
+	tiktok := newTestTikTok(t)
+	tiktok.eventsChanSize = 2
+	tiktok.backpressure = SpillToDisk
+	live := newTestLive(t, tiktok)
+
+	for i := 0; i < 10; i++ {
+		live.emit(GiftEvent{RepeatCount: i, eventMeta: eventMeta{isHistory: i%2 == 0}})
+	}
+
+	for i := 0; i < 10; i++ {
+		select {
+		case e := <-live.Events:
+			gift := e.(GiftEvent)
+			assert.Equal(t, i, gift.RepeatCount)
+			assert.Equal(t, i%2 == 0, gift.IsHistory())
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not delivered", i)
+		}
+	}
+	assert.Equal(t, uint64(0), live.DroppedEvents())
+}
+
+func TestBackpressureDisconnectEvent(t *testing.T) {
+	tiktok := newTestTikTok(t)
+	tiktok.eventsChanSize = 1
+	tiktok.backpressure = DropNewest
+	live := newTestLive(t, tiktok)
+
+	// The DisconnectEvent follows the policy like any other event and is counted when dropped.
+	live.emit(ChatEvent{Comment: "a"})
+	live.finish()
+	assert.Equal(t, uint64(1), live.DroppedEvents())
+
+	<-live.Events
+	live.finish()
+	_, ok := (<-live.Events).(*DisconnectEvent)
+	assert.True(t, ok)
+}
+
+func TestBackpressureBlockDisconnectEvent(t *testing.T) {
+	tiktok := newTestTikTok(t)
+	tiktok.eventsChanSize = 1
+	tiktok.backpressure = Block
+	live := newTestLive(t, tiktok)
+
+	// The live is done before its last events are sent, Block still delivers them.
+	live.emit(ChatEvent{Comment: "a"})
+	live.cancel()
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		live.finish()
+	}()
+	assert.Equal(t, "a", (<-live.Events).(ChatEvent).Comment)
+	select {
+	case e := <-live.Events:
+		assert.IsType(t, &DisconnectEvent{}, e)
+	case <-time.After(time.Second):
+		t.Fatal("DisconnectEvent not delivered")
+	}
+	<-finished
+	assert.Zero(t, live.DroppedEvents())
+}
+
+func TestSpillRoundTrip(t *testing.T) {
+	spill, err := newEventSpill(t.TempDir())
+	require.NoError(t, err)
+	defer spill.close()
+
+	created := time.Unix(1700000000, 123456789).UTC()
+	gift := GiftEvent{ID: 5655, Name: "Rose", RepeatCount: 3, User: &User{ID: 1}, eventMeta: eventMeta{isHistory: true}}
+	events := []Event{
+		gift,
+		// The unexported state of nested values must survive as well.
+		GiftStreakUpdateEvent{Gift: gift, Count: 3, Diamonds: 3, eventMeta: eventMeta{isHistory: true}},
+		GiftStreakEndEvent{Gift: gift, Count: 3, Diamonds: 3, TimedOut: true, eventMeta: eventMeta{isHistory: true}},
+		RoomEvent{Message: "alice sent Rose", DisplayText: DisplayText{Text: "alice sent Rose", Segments: []TextSegment{
+			{Type: TextUser, Text: "alice", User: &User{ID: 1}},
+			{Text: " sent "},
+			{Type: TextGift, Text: "Rose", GiftID: 5655},
+		}}},
+		ReconnectedEvent{Attempts: 2, eventMeta: eventMeta{created: created}},
+		&DisconnectEvent{eventMeta: eventMeta{created: created}},
+	}
+	for _, e := range events {
+		require.NoError(t, spill.push(e))
+	}
+	for _, want := range events {
+		got, err := spill.next(nil)
+		require.NoError(t, err)
+		spill.ack()
+		assert.Equal(t, want, got)
+	}
+}
+
+type unspillableEvent struct{}
+
+func (unspillableEvent) CreatedTimestamp() int64 { return 0 }
+func (unspillableEvent) IsHistory() bool         { return false }
+
+func TestSpillUnspillableEvent(t *testing.T) {
+	spill, err := newEventSpill(t.TempDir())
+	require.NoError(t, err)
+	defer spill.close()
+	assert.ErrorContains(t, spill.push(unspillableEvent{}), "does not implement spillState and restoreSpill")
+	assert.Zero(t, spill.pending())
+
+	// The live drops it and says so instead of losing it in the spill file.
+	tiktok := newTestTikTok(t)
+	tiktok.eventsChanSize = 1
+	tiktok.backpressure = SpillToDisk
+	live := newTestLive(t, tiktok)
+	live.emit(ChatEvent{Comment: "a"})
+	live.emit(unspillableEvent{})
+	assert.Equal(t, uint64(1), live.DroppedEvents())
+}
+
+// TestEventsSpillable checks that every event type of the package can be spilled, so SpillToDisk never drops them.
+func TestEventsSpillable(t *testing.T) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	require.NoError(t, err)
+	methods := make(map[string]map[string]bool)
+	embedsMeta := make(map[string]bool)
+	for _, file := range pkgs["gotiktoklive"].Files {
+		for _, decl := range file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok {
+				for _, spec := range gen.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok {
+						continue
+					}
+					for _, f := range st.Fields.List {
+						if id, ok := f.Type.(*ast.Ident); ok && len(f.Names) == 0 && id.Name == "eventMeta" {
+							embedsMeta[ts.Name.Name] = true
+						}
+					}
+				}
+				continue
+			}
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil {
+				continue
+			}
+			recv := fn.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			name := recv.(*ast.Ident).Name
+			if methods[name] == nil {
+				methods[name] = make(map[string]bool)
+			}
+			methods[name][fn.Name.Name] = true
+		}
+	}
+	for name, m := range methods {
+		if m["IsHistory"] && m["CreatedTimestamp"] {
+			assert.True(t, embedsMeta[name] || m["spillState"] && m["restoreSpill"], "%s cannot be spilled", name)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package gotiktoklive
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// BattlePhase is the phase of a link mic battle.
+type BattlePhase int
+
+const (
+	// BattleOngoing is while the hosts are collecting points.
+	BattleOngoing BattlePhase = iota
+	// BattlePunishment is after the battle was decided, while the losing hosts do their punishment.
+	BattlePunishment
+	// BattleFinished is once the punishment is over.
+	BattleFinished
+)
+
+func (p BattlePhase) String() string {
+	switch p {
+	case BattleOngoing:
+		return "ongoing"
+	case BattlePunishment:
+		return "punishment"
+	case BattleFinished:
+		return "finished"
+	}
+	return fmt.Sprintf("BattlePhase(%d)", int(p))
+}
+
+// BattleState is the state of a link mic battle, see Live.Battle. Teams are keyed by host ID in a one on one battle
+// and by team number in a two on two battle. WinnerID is the ID of the winning team once the battle ended, 0 on a
+// draw.
+type BattleState struct {
+	ID        int64
+	Phase     BattlePhase
+	StartTime int64
+	EndTime   int64
+	Teams     []BattleTeam
+	Tasks     []BattleTaskEvent
+	WinnerID  int64
+}
+
+// BattleTeam is a team of a link mic battle. TopViewers are the viewers who sent the most points, best first.
+type BattleTeam struct {
+	ID         int64
+	Hosts      []*User
+	Score      int
+	TopViewers []BattleViewer
+	WinStreak  int
+}
+
+// BattleViewer is a top viewer of a BattleTeam. Points is 0 for viewers only known from a BattlesEvent, which carries
+// the points of a group of viewers but not what each of them sent.
+type BattleViewer struct {
+	User   *User
+	Points int
+}
+
+// Winner returns the winning team. It returns false while the battle is ongoing or if it ended in a draw.
+func (b BattleState) Winner() (BattleTeam, bool) {
+	if b.Phase == BattleOngoing || b.WinnerID == 0 {
+		return BattleTeam{}, false
+	}
+	team := findBattleTeam(b.Teams, b.WinnerID)
+	if team == nil {
+		return BattleTeam{}, false
+	}
+	return *team, true
+}
+
+func (b BattleState) clone() BattleState {
+	b.Teams = slices.Clone(b.Teams)
+	b.Tasks = slices.Clone(b.Tasks)
+	return b
+}
+
+// findBattleTeam returns the team with the ID, or the team the host with the ID is on.
+func findBattleTeam(teams []BattleTeam, id int64) *BattleTeam {
+	for i := range teams {
+		if teams[i].ID == id {
+			return &teams[i]
+		}
+	}
+	for i := range teams {
+		for _, h := range teams[i].Hosts {
+			if h.ID == id {
+				return &teams[i]
+			}
+		}
+	}
+	return nil
+}
+
+func toBattleTeams(pt *pb.WebcastLinkMicBattle) []BattleTeam {
+	var teams []BattleTeam
+	hosts := make(map[int64]*User)
+	for _, h := range pt.HostTeam {
+		team := BattleTeam{ID: int64(h.Id)}
+		for _, group := range h.HostGroup {
+			for _, u := range group.Host {
+				user := battleUser(u.Id, "", u.ProfileId, u.Name, u.Images)
+				hosts[user.ID] = user
+				team.Hosts = append(team.Hosts, user)
+			}
+			team.Score += int(group.Points)
+		}
+		teams = append(teams, team)
+	}
+
+	if len(pt.HostData2V2) > 0 {
+		// In a two on two battle the hosts are grouped into numbered teams.
+		teams = nil
+		for _, d := range pt.HostData2V2 {
+			team := BattleTeam{
+				ID:    int64(d.TeamNumber),
+				Score: int(d.TotalPoints),
+			}
+			for _, h := range d.Hostdata {
+				user, ok := hosts[int64(h.HostId)]
+				if !ok {
+					user = battleUser(h.HostId, "", "", "", nil)
+				}
+				team.Hosts = append(team.Hosts, user)
+			}
+			teams = append(teams, team)
+		}
+	}
+
+	for _, d := range pt.Details {
+		if team := findBattleTeam(teams, int64(d.Id)); team != nil && d.Summary != nil && len(pt.HostData2V2) == 0 {
+			team.Score = max(team.Score, int(d.Summary.Points))
+		}
+	}
+
+	for _, v := range pt.ViewerTeam {
+		for _, group := range v.ViewerGroup {
+			// The viewer group is keyed by host ID in a one on one battle and by team number in a two on two battle.
+			id, err := strconv.ParseInt(group.HostIdOrTeamNum, 10, 64)
+			if err != nil || id == 0 {
+				id = int64(v.Id)
+			}
+			team := findBattleTeam(teams, id)
+			if team == nil {
+				continue
+			}
+			for _, viewer := range group.Viewer {
+				user := battleUser(viewer.Id, viewer.StringId, viewer.ProfileId, "", viewer.Images)
+				team.TopViewers = append(team.TopViewers, BattleViewer{User: user, Points: int(viewer.Points)})
+			}
+			sortBattleViewers(team.TopViewers)
+		}
+	}
+
+	for _, d := range pt.TeamData {
+		if team := findBattleTeam(teams, int64(d.TeamId)); team != nil {
+			team.WinStreak = int(d.Data.GetWinStreak())
+		}
+	}
+	return teams
+}
+
+func hasBattleViewer(viewers []BattleViewer, u *User) bool {
+	for _, v := range viewers {
+		if v.User == u || (u.Key() != "" && v.User.Key() == u.Key()) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortBattleViewers(viewers []BattleViewer) {
+	sort.SliceStable(viewers, func(i, j int) bool {
+		return viewers[i].Points > viewers[j].Points
+	})
+}
+
+// battleTaskID decodes the battle ID of a battle task message, which is not part of the generated proto message yet:
+//
+//	int64 battleId = 20;
+func battleTaskID(b []byte) int64 {
+	var id int64
+	walkProtoFields(b, func(num protowire.Number, v uint64, raw []byte) {
+		if num == 20 {
+			id = int64(v)
+		}
+	})
+	return id
+}
+
+// battleTracker correlates the link mic battle messages into the state of the current battle and emits
+// BattleStartedEvent, BattleScoreEvent and BattleEndedEvent.
+type battleTracker struct {
+	mu     sync.Mutex
+	battle *BattleState
+	// points of every host of the current battle, summed up into the score of its team.
+	points map[int64]int
+}
+
+func newBattleTracker() *battleTracker {
+	return &battleTracker{}
+}
+
+func (b *battleTracker) process(e Event) []Event {
+	switch e := e.(type) {
+	case MicBattleEvent:
+		finished := e.Status == pb.LinkMicBattleStatus_BATTLE_FINISHED || e.Status == pb.LinkMicBattleStatus_ARMY_FINISHED
+		return b.update(e.BattleID, finished, e.Timestamp, e.isHistory, func(battle *BattleState) {
+			battle.Teams = mergeBattleTeams(battle.Teams, e.Teams)
+		})
+	case BattlesEvent:
+		status := pb.LinkMicBattleStatus(e.Status)
+		finished := status == pb.LinkMicBattleStatus_BATTLE_FINISHED || status == pb.LinkMicBattleStatus_ARMY_FINISHED
+		return b.update(e.BattleID, finished, e.Timestamp, e.isHistory, func(battle *BattleState) {
+			for _, host := range e.Battles {
+				team := findBattleTeam(battle.Teams, host.Host)
+				if team == nil {
+					battle.Teams = append(battle.Teams, BattleTeam{ID: host.Host, Hosts: []*User{{ID: host.Host}}})
+					team = &battle.Teams[len(battle.Teams)-1]
+				}
+				points := 0
+				for _, g := range host.Groups {
+					points += g.Points
+					// The army only carries the points of the whole group, its users are added without points
+					// after the ranked viewers.
+					for _, u := range g.Users {
+						if !hasBattleViewer(team.TopViewers, u) {
+							team.TopViewers = append(team.TopViewers, BattleViewer{User: u})
+						}
+					}
+				}
+				b.points[host.Host] = points
+			}
+			b.score(battle)
+		})
+	case LinkMicFanTicketEvent:
+		b.mu.Lock()
+		ongoing := b.battle != nil && b.battle.Phase == BattleOngoing &&
+			(e.MatchID == 0 || e.MatchID == b.battle.ID)
+		b.mu.Unlock()
+		if !ongoing {
+			return nil
+		}
+		return b.update(e.MatchID, false, e.Timestamp, e.isHistory, func(battle *BattleState) {
+			for _, u := range e.Users {
+				if u.Score > 0 && findBattleTeam(battle.Teams, u.UserID) != nil {
+					b.points[u.UserID] = u.Score
+				}
+			}
+			b.score(battle)
+		})
+	case BattleTaskEvent:
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.battle != nil && (e.BattleID == 0 || e.BattleID == b.battle.ID) {
+			b.battle.Tasks = append(b.battle.Tasks, e)
+		}
+	case BattlePunishFinishEvent:
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.battle != nil && (e.BattleID == 0 || e.BattleID == b.battle.ID) {
+			b.battle.Phase = BattleFinished
+		}
+	}
+	return nil
+}
+
+// update applies a change to the battle with the ID, starting a new battle if it is not the current one, and returns
+// the battle event to emit for it. Updates after the battle ended only change the state.
+func (b *battleTracker) update(id int64, finished bool, ts int64, history bool, apply func(*BattleState)) []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	started := false
+	if b.battle != nil && b.battle.ID == 0 && b.battle.Phase == BattleOngoing {
+		// Started by a message without the battle ID.
+		b.battle.ID = id
+	}
+	if b.battle == nil || (id != 0 && id != b.battle.ID) || (id == 0 && !finished && b.battle.Phase != BattleOngoing) {
+		b.battle = &BattleState{ID: id, StartTime: ts}
+		b.points = make(map[int64]int)
+		started = true
+	}
+	battle := b.battle
+	apply(battle)
+	if battle.Phase != BattleOngoing {
+		return nil
+	}
+
+	switch {
+	case finished:
+		battle.Phase = BattlePunishment
+		battle.EndTime = ts
+		battle.WinnerID = battleWinner(battle.Teams)
+		return []Event{BattleEndedEvent{Timestamp: ts, Battle: battle.clone(), eventMeta: eventMeta{isHistory: history}}}
+	case started:
+		return []Event{BattleStartedEvent{Timestamp: ts, Battle: battle.clone(), eventMeta: eventMeta{isHistory: history}}}
+	}
+	return []Event{BattleScoreEvent{Timestamp: ts, Battle: battle.clone(), eventMeta: eventMeta{isHistory: history}}}
+}
+
+// score sums the points of the hosts into the scores of their teams. Teams without known points keep their score.
+func (b *battleTracker) score(battle *BattleState) {
+	for i := range battle.Teams {
+		team := &battle.Teams[i]
+		score, known := 0, false
+		for _, h := range team.Hosts {
+			if p, ok := b.points[h.ID]; ok {
+				score += p
+				known = true
+			}
+		}
+		if known {
+			team.Score = score
+		}
+	}
+}
+
+// mergeBattleTeams replaces the teams with the update, keeping the scores, top viewers and win streaks the update does
+// not carry.
+func mergeBattleTeams(known, update []BattleTeam) []BattleTeam {
+	if len(update) == 0 {
+		return known
+	}
+	merged := slices.Clone(update)
+	for i := range merged {
+		team := &merged[i]
+		old := findBattleTeam(known, team.ID)
+		if old == nil {
+			continue
+		}
+		if team.Score == 0 {
+			team.Score = old.Score
+		}
+		if len(team.TopViewers) == 0 {
+			team.TopViewers = old.TopViewers
+		}
+		if team.WinStreak == 0 {
+			team.WinStreak = old.WinStreak
+		}
+	}
+	return merged
+}
+
+// battleWinner returns the ID of the team with the highest score, or 0 on a draw.
+func battleWinner(teams []BattleTeam) int64 {
+	var winner int64
+	best := -1
+	for _, t := range teams {
+		switch {
+		case t.Score > best:
+			best = t.Score
+			winner = t.ID
+		case t.Score == best:
+			winner = 0
+		}
+	}
+	return winner
+}
+
+func (b *battleTracker) get() (BattleState, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.battle == nil {
+		return BattleState{}, false
+	}
+	return b.battle.clone(), true
+}
+
+// Battle returns the state of the current link mic battle, or the last one if none is running. It returns false if no
+// battle was seen on the live yet.
+func (l *Live) Battle() (BattleState, bool) {
+	return l.battles.get()
+}
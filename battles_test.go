@@ -0,0 +1,208 @@
+package gotiktoklive
+
+import (
+	"testing"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestBattleLifecycle(t *testing.T) {
+	live := newTestLive(t, nil)
+	var started, scores, ended []BattleState
+	live.OnBattleStarted(func(e BattleStartedEvent) { started = append(started, e.Battle) }, HandlerInline())
+	live.OnBattleScore(func(e BattleScoreEvent) { scores = append(scores, e.Battle) }, HandlerInline())
+	live.OnBattleEnded(func(e BattleEndedEvent) { ended = append(ended, e.Battle) }, HandlerInline())
+
+	_, ok := live.Battle()
+	assert.False(t, ok)
+
+	e := parseTestMsg(t, &pb.WebcastLinkMicBattle{
+		Common:       &pb.Common{MsgId: 7700000000000000001, CreateTime: 1000},
+		Id:           99,
+		BattleStatus: pb.LinkMicBattleStatus_BATTLE_ONGOING,
+		HostTeam: []*pb.WebcastLinkMicBattle_LinkMicBattleHost{
+			{Id: 1, HostGroup: []*pb.WebcastLinkMicBattle_LinkMicBattleHost_HostGroup{{Host: []*pb.WebcastLinkMicBattle_LinkMicBattleHost_HostGroup_Host{{Id: 1, Name: "host"}}}}},
+			{Id: 2, HostGroup: []*pb.WebcastLinkMicBattle_LinkMicBattleHost_HostGroup{{Host: []*pb.WebcastLinkMicBattle_LinkMicBattleHost_HostGroup_Host{{Id: 2, Name: "rival"}}}}},
+		},
+		ViewerTeam: []*pb.WebcastLinkMicBattle_LinkMicBattleTopViewers{{
+			Id: 1,
+			ViewerGroup: []*pb.WebcastLinkMicBattle_LinkMicBattleTopViewers_TopViewerGroup{{
+				HostIdOrTeamNum: "1",
+				Viewer: []*pb.WebcastLinkMicBattle_LinkMicBattleTopViewers_TopViewerGroup_TopViewer{
+					{Id: 10, Points: 5},
+					{Id: 11, Points: 50},
+				},
+			}},
+		}},
+		TeamData: []*pb.WebcastLinkMicBattle_LinkMicBattleTeamData{{TeamId: 2, Data: &pb.WebcastLinkMicBattle_LinkMicBattleData{WinStreak: 3}}},
+	})
+	require.IsType(t, MicBattleEvent{}, e)
+	mic := e.(MicBattleEvent)
+	assert.Equal(t, int64(99), mic.BattleID)
+	assert.Len(t, mic.Users, 2)
+	require.Len(t, mic.Teams, 2)
+	assert.Equal(t, int64(11), mic.Teams[0].TopViewers[0].User.ID)
+	assert.Equal(t, 3, mic.Teams[1].WinStreak)
+	live.emit(e)
+
+	live.emit(parseTestMsg(t, &pb.WebcastLinkMicArmies{
+		Common:       &pb.Common{MsgId: 7700000000000000002, CreateTime: 2000},
+		Id:           99,
+		BattleStatus: pb.LinkMicBattleStatus_ARMY_ONGOING,
+		BattleItems: []*pb.LinkMicArmiesItems{
+			{HostUserId: 1, BattleGroups: []*pb.LinkMicArmiesItems_LinkMicArmiesGroup{{Points: 30, Users: []*pb.User{{Id: 11}, {Id: 12}}}, {Points: 20}}},
+			{HostUserId: 2, BattleGroups: []*pb.LinkMicArmiesItems_LinkMicArmiesGroup{{Points: 40}}},
+		},
+	}))
+
+	task := &pb.WebcastLinkmicBattleTaskMessage{Header: &pb.Common{MsgId: 7700000000000000003}, Data2: 1}
+	task.ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 20, protowire.VarintType), 99))
+	e = parseTestMsg(t, task)
+	require.IsType(t, BattleTaskEvent{}, e)
+	assert.Equal(t, int64(99), e.(BattleTaskEvent).BattleID)
+	live.emit(e)
+
+	live.emit(parseTestMsg(t, &pb.WebcastLinkMicBattle{
+		Common:       &pb.Common{MsgId: 7700000000000000004, CreateTime: 3000},
+		Id:           99,
+		BattleStatus: pb.LinkMicBattleStatus_BATTLE_FINISHED,
+	}))
+	live.emit(parseTestMsg(t, &pb.WebcastLinkMicBattlePunishFinish{
+		Header: &pb.Common{MsgId: 7700000000000000005, CreateTime: 4000},
+		Id2:    99,
+	}))
+
+	require.Len(t, started, 1)
+	assert.Equal(t, int64(99), started[0].ID)
+	require.Len(t, scores, 1)
+	assert.Equal(t, 50, scores[0].Teams[0].Score)
+	assert.Equal(t, 40, scores[0].Teams[1].Score)
+	// The army adds its users without taking the points of the whole group as theirs.
+	viewers := scores[0].Teams[0].TopViewers
+	require.Len(t, viewers, 3)
+	assert.Equal(t, []int64{11, 10, 12}, []int64{viewers[0].User.ID, viewers[1].User.ID, viewers[2].User.ID})
+	assert.Equal(t, []int{50, 5, 0}, []int{viewers[0].Points, viewers[1].Points, viewers[2].Points})
+	require.Len(t, ended, 1)
+	assert.Equal(t, BattlePunishment, ended[0].Phase)
+	assert.Equal(t, int64(3000), ended[0].EndTime)
+	winner, ok := ended[0].Winner()
+	require.True(t, ok)
+	assert.Equal(t, "host", winner.Hosts[0].Nickname)
+
+	battle, ok := live.Battle()
+	require.True(t, ok)
+	assert.Equal(t, BattleFinished, battle.Phase)
+	assert.Len(t, battle.Tasks, 1)
+}
+
+func TestBattleTwoOnTwo(t *testing.T) {
+	teams := toBattleTeams(&pb.WebcastLinkMicBattle{
+		HostData2V2: []*pb.WebcastLinkMicBattle_Host2V2Data{
+			{TeamNumber: 1, TotalPoints: 10, Hostdata: []*pb.WebcastLinkMicBattle_Host2V2Data_HostData{{HostId: 1}, {HostId: 2}}},
+			{TeamNumber: 2, TotalPoints: 10, Hostdata: []*pb.WebcastLinkMicBattle_Host2V2Data_HostData{{HostId: 3}, {HostId: 4}}},
+		},
+	})
+	require.Len(t, teams, 2)
+	assert.Equal(t, int64(2), findBattleTeam(teams, 4).ID)
+	assert.Equal(t, int64(0), battleWinner(teams))
+
+	tracker := newBattleTracker()
+	events := tracker.process(BattlesEvent{Status: int(pb.LinkMicBattleStatus_ARMY_ONGOING), Battles: []*Battle{{Host: 5}}})
+	require.Len(t, events, 1)
+	assert.IsType(t, BattleStartedEvent{}, events[0])
+	// The ID of the battle is picked up once known.
+	events = tracker.process(MicBattleEvent{BattleID: 7, Status: pb.LinkMicBattleStatus_BATTLE_ONGOING, Teams: teams})
+	require.Len(t, events, 1)
+	require.IsType(t, BattleScoreEvent{}, events[0])
+	assert.Equal(t, int64(7), events[0].(BattleScoreEvent).Battle.ID)
+}
+
+func TestBattleTransitions(t *testing.T) {
+	teams := []BattleTeam{{ID: 1, Hosts: []*User{{ID: 1}}}, {ID: 2, Hosts: []*User{{ID: 2}}}}
+	tracker := newBattleTracker()
+	phase := func() BattlePhase {
+		battle, ok := tracker.get()
+		require.True(t, ok)
+		return battle.Phase
+	}
+
+	events := tracker.process(MicBattleEvent{BattleID: 1, Status: pb.LinkMicBattleStatus_BATTLE_ONGOING, Teams: teams, Timestamp: 100})
+	require.Len(t, events, 1)
+	require.IsType(t, BattleStartedEvent{}, events[0])
+	assert.Equal(t, int64(100), events[0].(BattleStartedEvent).Battle.StartTime)
+	assert.Equal(t, BattleOngoing, phase())
+
+	// Fan tickets of the hosts score the battle, those of other users are ignored.
+	events = tracker.process(LinkMicFanTicketEvent{MatchID: 1, Users: []FanTicket{{UserID: 1, Score: 30}, {UserID: 2, Score: 10}, {UserID: 9, Score: 99}}})
+	require.Len(t, events, 1)
+	require.IsType(t, BattleScoreEvent{}, events[0])
+	battle := events[0].(BattleScoreEvent).Battle
+	assert.Equal(t, []int{30, 10}, []int{battle.Teams[0].Score, battle.Teams[1].Score})
+
+	events = tracker.process(MicBattleEvent{BattleID: 1, Status: pb.LinkMicBattleStatus_BATTLE_FINISHED, Timestamp: 200})
+	require.Len(t, events, 1)
+	require.IsType(t, BattleEndedEvent{}, events[0])
+	battle = events[0].(BattleEndedEvent).Battle
+	assert.Equal(t, BattlePunishment, battle.Phase)
+	assert.Equal(t, int64(200), battle.EndTime)
+	assert.Equal(t, int64(1), battle.WinnerID)
+
+	// Once ended, late scores and repeated ends change nothing and emit nothing.
+	assert.Empty(t, tracker.process(LinkMicFanTicketEvent{MatchID: 1, Users: []FanTicket{{UserID: 2, Score: 50}}}))
+	assert.Empty(t, tracker.process(MicBattleEvent{BattleID: 1, Status: pb.LinkMicBattleStatus_BATTLE_FINISHED, Timestamp: 300}))
+	battle, _ = tracker.get()
+	assert.Equal(t, int64(200), battle.EndTime)
+	assert.Equal(t, int64(1), battle.WinnerID)
+
+	// The punishment of another battle does not end this one.
+	tracker.process(BattlePunishFinishEvent{BattleID: 5})
+	assert.Equal(t, BattlePunishment, phase())
+	tracker.process(BattlePunishFinishEvent{BattleID: 1})
+	assert.Equal(t, BattleFinished, phase())
+
+	// A new battle starts over with fresh scores.
+	events = tracker.process(MicBattleEvent{BattleID: 2, Status: pb.LinkMicBattleStatus_BATTLE_ONGOING, Teams: teams, Timestamp: 400})
+	require.Len(t, events, 1)
+	require.IsType(t, BattleStartedEvent{}, events[0])
+	battle = events[0].(BattleStartedEvent).Battle
+	assert.Equal(t, int64(2), battle.ID)
+	assert.Equal(t, []int{0, 0}, []int{battle.Teams[0].Score, battle.Teams[1].Score})
+	assert.Equal(t, BattleOngoing, phase())
+}
+
+func TestBattleUserIdentity(t *testing.T) {
+	chat := parseTestMsg(t, &pb.WebcastChatMessage{
+		Common:  &pb.Common{MsgId: 7700000000000000031},
+		User:    &pb.User{Id: 11, DisplayId: "viewer", Nickname: "Viewer"},
+		Content: "hi",
+	}).(ChatEvent)
+
+	e := parseTestMsg(t, &pb.WebcastLinkMicBattle{
+		Common: &pb.Common{MsgId: 7700000000000000032},
+		HostTeam: []*pb.WebcastLinkMicBattle_LinkMicBattleHost{
+			{Id: 1, HostGroup: []*pb.WebcastLinkMicBattle_LinkMicBattleHost_HostGroup{{Host: []*pb.WebcastLinkMicBattle_LinkMicBattleHost_HostGroup_Host{{Id: 1, ProfileId: "host", Name: "Host"}}}}},
+		},
+		ViewerTeam: []*pb.WebcastLinkMicBattle_LinkMicBattleTopViewers{{
+			Id: 1,
+			ViewerGroup: []*pb.WebcastLinkMicBattle_LinkMicBattleTopViewers_TopViewerGroup{{
+				HostIdOrTeamNum: "1",
+				Viewer: []*pb.WebcastLinkMicBattle_LinkMicBattleTopViewers_TopViewerGroup_TopViewer{
+					{StringId: "11", ProfileId: "viewer", Points: 5},
+				},
+			}},
+		}},
+	}).(MicBattleEvent)
+	require.Len(t, e.Teams, 1)
+	host := e.Teams[0].Hosts[0]
+	assert.Equal(t, "host", host.Username)
+	assert.Equal(t, "Host", host.Nickname)
+	assert.Equal(t, host.Key(), e.Users[0].Key())
+
+	// Viewers that only carry the ID as a string get the same key as in chat.
+	viewer := e.Teams[0].TopViewers[0].User
+	assert.Equal(t, "viewer", viewer.Username)
+	assert.Equal(t, chat.User.Key(), viewer.Key())
+}
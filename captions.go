@@ -0,0 +1,143 @@
+package gotiktoklive
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxCaptionDuration is how long a caption is shown at most when no next caption replaces it earlier.
+	maxCaptionDuration = 5 * time.Second
+)
+
+// SubtitleFormat is the file format written by a CaptionWriter.
+type SubtitleFormat int
+
+const (
+	SRT SubtitleFormat = iota
+	WebVTT
+)
+
+func (f SubtitleFormat) String() string {
+	switch f {
+	case SRT:
+		return "srt"
+	case WebVTT:
+		return "vtt"
+	}
+	return fmt.Sprintf("SubtitleFormat(%d)", int(f))
+}
+
+// CaptionWriter writes CaptionEvents as SRT or WebVTT subtitles with cue times relative to the start of the stream.
+// A caption is shown until the next one starts, at most 5 seconds. Close must be called to write the last caption.
+type CaptionWriter struct {
+	// Language only writes captions in this language if set.
+	Language string
+
+	w       io.Writer
+	format  SubtitleFormat
+	start   time.Time
+	mu      sync.Mutex
+	header  bool
+	cue     int
+	pending *CaptionEvent
+}
+
+// NewCaptionWriter creates a CaptionWriter for a stream that started at start.
+func NewCaptionWriter(w io.Writer, format SubtitleFormat, start time.Time) *CaptionWriter {
+	return &CaptionWriter{
+		w:      w,
+		format: format,
+		start:  start,
+	}
+}
+
+// Write adds a caption. It is written once the next caption arrived or the writer is closed.
+func (c *CaptionWriter) Write(e CaptionEvent) error {
+	if c.Language != "" && e.Language != c.Language {
+		return nil
+	}
+	if strings.TrimSpace(e.Text) == "" {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var err error
+	if c.pending != nil {
+		err = c.writeCue(*c.pending, e.Time)
+	}
+	c.pending = &e
+	return err
+}
+
+// Close writes the last caption. It does not close the underlying writer.
+func (c *CaptionWriter) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending != nil {
+		e := *c.pending
+		c.pending = nil
+		return c.writeCue(e, e.Time.Add(maxCaptionDuration))
+	}
+	return c.writeHeader()
+}
+
+func (c *CaptionWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	if c.format == WebVTT {
+		_, err := io.WriteString(c.w, "WEBVTT\n\n")
+		return err
+	}
+	return nil
+}
+
+func (c *CaptionWriter) writeCue(e CaptionEvent, next time.Time) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	start := max(e.Time.Sub(c.start), 0)
+	end := min(max(next.Sub(c.start), start), start+maxCaptionDuration)
+	text := strings.TrimSpace(e.Text)
+
+	c.cue++
+	var err error
+	switch c.format {
+	case WebVTT:
+		_, err = fmt.Fprintf(c.w, "%s --> %s\n%s\n\n", formatCueTime(start, '.'), formatCueTime(end, '.'), text)
+	default:
+		_, err = fmt.Fprintf(c.w, "%d\n%s --> %s\n%s\n\n", c.cue, formatCueTime(start, ','), formatCueTime(end, ','), text)
+	}
+	return err
+}
+
+// formatCueTime formats d as hh:mm:ss followed by sep and milliseconds.
+func formatCueTime(d time.Duration, sep byte) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// RecordCaptions writes the captions of the live to w as subtitles for a recording of the stream, with times relative
+// to Live.Info.CreateTime. Call the returned function to stop recording, it writes the last caption.
+func (l *Live) RecordCaptions(w io.Writer, format SubtitleFormat) (stop func() error) {
+	var start time.Time
+	if l.Info != nil {
+		start = time.Unix(l.Info.CreateTime, 0)
+	}
+	c := NewCaptionWriter(w, format, start)
+	remove := l.OnCaption(func(e CaptionEvent) {
+		if err := c.Write(e); err != nil {
+			l.t.errHandler(fmt.Errorf("cannot write caption: %w", err))
+		}
+	}, HandlerInline())
+	return func() error {
+		remove()
+		return c.Close()
+	}
+}
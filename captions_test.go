@@ -0,0 +1,73 @@
+package gotiktoklive
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptionWriter(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	captions := []CaptionEvent{
+		{Time: start.Add(1500 * time.Millisecond), Language: "en", Text: "hello everyone"},
+		{Time: start.Add(3 * time.Second), Language: "de", Text: "hallo"},
+		{Time: start.Add(time.Hour + 2*time.Second), Language: "en", Text: " welcome back "},
+		{Time: start.Add(time.Hour + 2*time.Second), Language: "en", Text: ""},
+	}
+
+	var srt bytes.Buffer
+	w := NewCaptionWriter(&srt, SRT, start)
+	w.Language = "en"
+	for _, c := range captions {
+		require.NoError(t, w.Write(c))
+	}
+	require.NoError(t, w.Close())
+	assert.Equal(t, "1\n00:00:01,500 --> 00:00:06,500\nhello everyone\n\n"+
+		"2\n01:00:02,000 --> 01:00:07,000\nwelcome back\n\n", srt.String())
+
+	var vtt bytes.Buffer
+	w = NewCaptionWriter(&vtt, WebVTT, start)
+	for _, c := range captions {
+		require.NoError(t, w.Write(c))
+	}
+	require.NoError(t, w.Close())
+	assert.Equal(t, "WEBVTT\n\n"+
+		"00:00:01.500 --> 00:00:03.000\nhello everyone\n\n"+
+		"00:00:03.000 --> 00:00:08.000\nhallo\n\n"+
+		"01:00:02.000 --> 01:00:07.000\nwelcome back\n\n", vtt.String())
+
+	vtt.Reset()
+	require.NoError(t, NewCaptionWriter(&vtt, WebVTT, start).Close())
+	assert.Equal(t, "WEBVTT\n\n", vtt.String())
+}
+
+func TestRecordCaptions(t *testing.T) {
+	live := newTestLive(t, nil)
+	live.Info = &RoomInfo{CreateTime: 1700000000}
+
+	e := parseTestMsg(t, &pb.WebcastCaptionMessage{
+		Common:      &pb.Common{MsgId: 7500000000000000001, CreateTime: 1700000009000},
+		TimeStamp:   1700000002,
+		CaptionData: &pb.WebcastCaptionMessage_CaptionData{Language: "en", Text: "first"},
+	})
+	require.IsType(t, CaptionEvent{}, e)
+	assert.Equal(t, time.Unix(1700000002, 0), e.(CaptionEvent).Time)
+
+	var buf bytes.Buffer
+	stop := live.RecordCaptions(&buf, SRT)
+	live.emit(e)
+	live.emit(parseTestMsg(t, &pb.WebcastCaptionMessage{
+		Common:      &pb.Common{MsgId: 7500000000000000002, CreateTime: 1700000004000},
+		CaptionData: &pb.WebcastCaptionMessage_CaptionData{Language: "en", Text: "second"},
+	}))
+	require.NoError(t, stop())
+	// Not written after stopping.
+	live.emit(CaptionEvent{Time: time.Unix(1700000010, 0), Text: "third"})
+
+	assert.Equal(t, "1\n00:00:02,000 --> 00:00:04,000\nfirst\n\n"+
+		"2\n00:00:04,000 --> 00:00:09,000\nsecond\n\n", buf.String())
+}
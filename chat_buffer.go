@@ -0,0 +1,95 @@
+package gotiktoklive
+
+import (
+	"slices"
+	"sync"
+)
+
+// BufferedChat is a chat message kept by the recent chat buffer, see EnableChatBuffer.
+type BufferedChat struct {
+	ChatEvent
+	// Deleted is set once a moderator removed the message or its author.
+	Deleted bool
+	// DeletedAt is the timestamp of the MessageDeletedEvent that removed the message.
+	DeletedAt int64
+}
+
+// chatBuffer keeps the most recent chat messages of a live so moderation can be applied after the fact. The messages
+// are kept in a ring, the oldest at head.
+type chatBuffer struct {
+	mu    sync.Mutex
+	chats []BufferedChat
+	head  int
+	count int
+	ids   map[int64]struct{}
+}
+
+func newChatBuffer(size int) *chatBuffer {
+	return &chatBuffer{
+		chats: make([]BufferedChat, size),
+		ids:   make(map[int64]struct{}, size),
+	}
+}
+
+// at returns the i-th message, oldest first.
+func (c *chatBuffer) at(i int) *BufferedChat {
+	return &c.chats[(c.head+i)%len(c.chats)]
+}
+
+func (c *chatBuffer) process(e Event) []Event {
+	switch e := e.(type) {
+	case ChatEvent:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if _, ok := c.ids[e.MessageID]; ok && e.MessageID != 0 {
+			// Sent again as history after a reconnect.
+			return nil
+		}
+		if c.count == len(c.chats) {
+			// Overwrite the oldest message.
+			delete(c.ids, c.chats[c.head].MessageID)
+			c.chats[c.head] = BufferedChat{ChatEvent: e}
+			c.head = (c.head + 1) % len(c.chats)
+		} else {
+			*c.at(c.count) = BufferedChat{ChatEvent: e}
+			c.count++
+		}
+		c.ids[e.MessageID] = struct{}{}
+	case MessageDeletedEvent:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i := 0; i < c.count; i++ {
+			chat := c.at(i)
+			if chat.Deleted {
+				continue
+			}
+			if slices.Contains(e.MessageIDs, chat.MessageID) ||
+				(chat.User != nil && slices.Contains(e.UserIDs, chat.User.ID)) {
+				chat.Deleted = true
+				chat.DeletedAt = e.Timestamp
+			}
+		}
+	}
+	return nil
+}
+
+func (c *chatBuffer) recent(includeDeleted bool) []BufferedChat {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	chats := make([]BufferedChat, 0, c.count)
+	for i := 0; i < c.count; i++ {
+		if chat := c.at(i); includeDeleted || !chat.Deleted {
+			chats = append(chats, *chat)
+		}
+	}
+	return chats
+}
+
+// RecentChats returns the chat messages kept by the recent chat buffer, oldest first. Messages removed by moderators
+// are marked as deleted, or left out if includeDeleted is false. It returns nil unless EnableChatBuffer was used.
+func (l *Live) RecentChats(includeDeleted bool) []BufferedChat {
+	if l.chats == nil {
+		return nil
+	}
+	return l.chats.recent(includeDeleted)
+}
@@ -0,0 +1,63 @@
+package gotiktoklive
+
+import (
+	"testing"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatBuffer(t *testing.T) {
+	tiktok := newTestTikTok(t)
+	tiktok.chatBufferSize = 3
+	live := newTestLive(t, tiktok)
+
+	spammer := &User{ID: 66}
+	live.emit(ChatEvent{MessageID: 1, Comment: "dropped", User: &User{ID: 1}})
+	live.emit(ChatEvent{MessageID: 2, Comment: "hello", User: &User{ID: 2}})
+	live.emit(ChatEvent{MessageID: 3, Comment: "spam", User: spammer})
+	live.emit(ChatEvent{MessageID: 4, Comment: "rude", User: &User{ID: 4}})
+	// Repeated as history after a reconnect.
+	live.emit(ChatEvent{MessageID: 4, Comment: "rude", User: &User{ID: 4}, eventMeta: eventMeta{isHistory: true}})
+
+	e := parseTestMsg(t, &pb.WebcastImDeleteMessage{
+		Common:            &pb.Common{MsgId: 7400000000000000001, CreateTime: 1000},
+		DeleteMsgIdsList:  []int64{4},
+		DeleteUserIdsList: []int64{spammer.ID},
+	})
+	require.IsType(t, MessageDeletedEvent{}, e)
+	live.emit(e)
+
+	chats := live.RecentChats(true)
+	require.Len(t, chats, 3)
+	assert.Equal(t, "hello", chats[0].Comment)
+	assert.False(t, chats[0].Deleted)
+	assert.True(t, chats[1].Deleted)
+	assert.True(t, chats[2].Deleted)
+	assert.Equal(t, int64(1000), chats[2].DeletedAt)
+
+	chats = live.RecentChats(false)
+	if assert.Len(t, chats, 1) {
+		assert.Equal(t, "hello", chats[0].Comment)
+	}
+
+	assert.Nil(t, newTestLive(t, nil).RecentChats(true))
+}
+
+func TestChatBufferWraps(t *testing.T) {
+	c := newChatBuffer(3)
+	for id := int64(1); id <= 10; id++ {
+		c.process(ChatEvent{MessageID: id})
+	}
+	var ids []int64
+	for _, chat := range c.recent(true) {
+		ids = append(ids, chat.MessageID)
+	}
+	assert.Equal(t, []int64{8, 9, 10}, ids)
+	assert.Len(t, c.ids, 3)
+
+	// Messages that left the buffer are not known anymore.
+	c.process(ChatEvent{MessageID: 7})
+	assert.Equal(t, int64(7), c.recent(true)[2].MessageID)
+}
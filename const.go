@@ -101,8 +101,11 @@ var (
 	ErrFFMPEGNotFound    = errors.New("please install ffmpeg before downloading")
 	ErrRateLimitExceeded = errors.New("you have exceeded the rate limit, please wait a few min")
 	ErrUserInfoNotFound  = errors.New("user info not found")
+	ErrTikTokClosed      = errors.New("tiktok instance has been closed")
 )
 
+var errNotConnected = errors.New("websocket not connected")
+
 type ErrIPBlockedOrBanned struct{}
 
 func (e ErrIPBlockedOrBanned) Error() string {
@@ -0,0 +1,194 @@
+package gotiktoklive
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// TextSegmentType is the kind of a TextSegment.
+type TextSegmentType int
+
+const (
+	// TextPlain is literal text of the pattern or a string placeholder.
+	TextPlain TextSegmentType = iota
+	// TextUser is a user placeholder, User is set.
+	TextUser
+	// TextGift is a gift placeholder, GiftID is set.
+	TextGift
+)
+
+func (t TextSegmentType) String() string {
+	switch t {
+	case TextPlain:
+		return "plain"
+	case TextUser:
+		return "user"
+	case TextGift:
+		return "gift"
+	}
+	return fmt.Sprintf("TextSegmentType(%d)", int(t))
+}
+
+// DisplayText is a message TikTok renders from a pattern such as "{0:user} shared the LIVE", with the placeholders
+// filled from a list of pieces. Text is the rendered plain text, Segments the same text split into the literal parts
+// and placeholders so users and gifts can be rendered differently.
+type DisplayText struct {
+	Key      string
+	Pattern  string
+	Text     string
+	Segments []TextSegment
+}
+
+// TextSegment is a part of a DisplayText. Gifts TikTok sent without a name are named from the gift catalog of the
+// room, or "gift" when it does not have them either.
+type TextSegment struct {
+	Type   TextSegmentType
+	Text   string
+	User   *User
+	GiftID int
+}
+
+// String returns the plain text.
+func (d DisplayText) String() string {
+	return d.Text
+}
+
+func toDisplayText(t *pb.Text) DisplayText {
+	if t == nil {
+		return DisplayText{}
+	}
+	d := DisplayText{
+		Key:     t.Key,
+		Pattern: t.DefaultPattern,
+	}
+	var text strings.Builder
+	add := func(s TextSegment) {
+		if s.Text == "" && s.Type == TextPlain {
+			return
+		}
+		text.WriteString(s.Text)
+		if n := len(d.Segments); n > 0 && s.Type == TextPlain && d.Segments[n-1].Type == TextPlain {
+			d.Segments[n-1].Text += s.Text
+			return
+		}
+		d.Segments = append(d.Segments, s)
+	}
+
+	pattern := t.DefaultPattern
+	for len(pattern) > 0 {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			add(TextSegment{Text: pattern})
+			break
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			add(TextSegment{Text: pattern})
+			break
+		}
+		end += start
+		add(TextSegment{Text: pattern[:start]})
+		if s, ok := toTextSegment(pattern[start+1:end], t.PiecesList); ok {
+			add(s)
+		} else {
+			add(TextSegment{Text: pattern[start : end+1]})
+		}
+		pattern = pattern[end+1:]
+	}
+	d.Text = text.String()
+	return d
+}
+
+// unnamed reports whether the segment is a gift placeholder rendered without the name of the gift.
+func (s TextSegment) unnamed() bool {
+	return s.Type == TextGift && s.Text == TextGift.String()
+}
+
+// hasUnnamedGifts reports whether a gift placeholder was rendered without the name of the gift.
+func (d DisplayText) hasUnnamedGifts() bool {
+	return slices.ContainsFunc(d.Segments, TextSegment.unnamed)
+}
+
+// nameGifts renders the unnamed gift placeholders with the name returned by name, if any.
+func (d DisplayText) nameGifts(name func(id int) string) DisplayText {
+	segments := slices.Clone(d.Segments)
+	var text strings.Builder
+	for i, s := range segments {
+		if s.unnamed() {
+			if n := name(s.GiftID); n != "" {
+				segments[i].Text = n
+			}
+		}
+		text.WriteString(segments[i].Text)
+	}
+	d.Segments = segments
+	d.Text = text.String()
+	return d
+}
+
+// toTextSegment fills a placeholder such as "0:user" from the piece with its index. Pieces without a value are
+// rendered as the name of the placeholder type.
+func toTextSegment(placeholder string, pieces []*pb.Text_TextPiece) (TextSegment, bool) {
+	idx, kind, _ := strings.Cut(placeholder, ":")
+	i, err := strconv.Atoi(idx)
+	if err != nil || i < 0 || i >= len(pieces) {
+		return TextSegment{}, false
+	}
+	piece := pieces[i]
+	fallback := piece.StringValue
+	if fallback == "" && piece.PatternRefValue != nil {
+		fallback = piece.PatternRefValue.DefaultPattern
+	}
+	if fallback == "" {
+		fallback = kind
+	}
+
+	switch v := piece.TextPieceType.(type) {
+	case *pb.Text_TextPiece_UserValue:
+		user := toUser(v.UserValue.GetUser())
+		name := user.Nickname
+		if name == "" {
+			name = user.Username
+		}
+		if name == "" {
+			name = fallback
+		}
+		return TextSegment{Type: TextUser, Text: name, User: user}, true
+	case *pb.Text_TextPiece_GiftValue:
+		s := TextSegment{Type: TextGift, Text: giftPieceName(v.GiftValue), GiftID: int(v.GiftValue.GetGiftId())}
+		if s.Text == "" && fallback != kind {
+			s.Text = fallback
+		}
+		if s.Text == "" {
+			s.Text = TextGift.String()
+		}
+		return s, true
+	}
+	return TextSegment{Text: fallback}, true
+}
+
+// giftPieceName returns the name of the gift from the nameRef pattern of the piece, which is missing from the
+// generated code.
+func giftPieceName(gift *pb.Text_TextPieceGift) string {
+	if gift == nil {
+		return ""
+	}
+	var name string
+	walkProtoFields(gift.ProtoReflect().GetUnknown(), func(num protowire.Number, v uint64, raw []byte) {
+		if num != 2 {
+			return
+		}
+		// PatternRef{key = 1, defaultPattern = 2}
+		walkProtoFields(raw, func(num protowire.Number, v uint64, raw []byte) {
+			if num == 2 {
+				name = string(raw)
+			}
+		})
+	})
+	return name
+}
@@ -0,0 +1,104 @@
+package gotiktoklive
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestDisplayText(t *testing.T) {
+	text := toDisplayText(&pb.Text{
+		Key:            "live_room_gift_msg",
+		DefaultPattern: "{0:user} sent {1:gift} x{2:string} {3:user} {missing}",
+		PiecesList: []*pb.Text_TextPiece{
+			{TextPieceType: &pb.Text_TextPiece_UserValue{UserValue: &pb.Text_TextPieceUser{User: &pb.User{Id: 1, Nickname: "alice"}}}},
+			{TextPieceType: &pb.Text_TextPiece_GiftValue{GiftValue: &pb.Text_TextPieceGift{GiftId: 5655}}},
+			{StringValue: "3"},
+		},
+	})
+	assert.Equal(t, "live_room_gift_msg", text.Key)
+	assert.Equal(t, "alice sent gift x3 {3:user} {missing}", text.Text)
+	require.Len(t, text.Segments, 4)
+	assert.Equal(t, TextUser, text.Segments[0].Type)
+	assert.Equal(t, int64(1), text.Segments[0].User.ID)
+	assert.Equal(t, TextSegment{Text: " sent "}, text.Segments[1])
+	assert.Equal(t, TextSegment{Type: TextGift, Text: "gift", GiftID: 5655}, text.Segments[2])
+	assert.Equal(t, TextSegment{Text: " x3 {3:user} {missing}"}, text.Segments[3])
+
+	assert.Equal(t, "unterminated {0", toDisplayText(&pb.Text{DefaultPattern: "unterminated {0"}).Text)
+	assert.Equal(t, DisplayText{}, toDisplayText(nil))
+}
+
+func TestDisplayTextGiftName(t *testing.T) {
+	// The name of the gift is in the nameRef pattern of the piece, which the generated code leaves out.
+	gift := &pb.Text_TextPieceGift{GiftId: 5655}
+	var nameRef []byte
+	nameRef = protowire.AppendTag(nameRef, 1, protowire.BytesType)
+	nameRef = protowire.AppendString(nameRef, "gift_name_5655")
+	nameRef = protowire.AppendTag(nameRef, 2, protowire.BytesType)
+	nameRef = protowire.AppendString(nameRef, "Rose")
+	var unknown []byte
+	unknown = protowire.AppendTag(unknown, 2, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, nameRef)
+	gift.ProtoReflect().SetUnknown(unknown)
+
+	pieces := []*pb.Text_TextPiece{
+		{TextPieceType: &pb.Text_TextPiece_UserValue{UserValue: &pb.Text_TextPieceUser{User: &pb.User{Id: 1, Nickname: "alice"}}}},
+		{TextPieceType: &pb.Text_TextPiece_GiftValue{GiftValue: gift}},
+		{TextPieceType: &pb.Text_TextPiece_GiftValue{GiftValue: &pb.Text_TextPieceGift{GiftId: 5269}}},
+		{TextPieceType: &pb.Text_TextPiece_GiftValue{GiftValue: &pb.Text_TextPieceGift{GiftId: 1}}},
+	}
+	text := toDisplayText(&pb.Text{DefaultPattern: "{0:user} sent {1:gift}, {2:gift} and {3:gift}", PiecesList: pieces})
+	assert.Equal(t, "alice sent Rose, gift and gift", text.Text)
+	assert.Equal(t, TextSegment{Type: TextGift, Text: "Rose", GiftID: 5655}, text.Segments[2])
+	assert.True(t, text.hasUnnamedGifts())
+
+	// Gifts the pieces do not name are named from the catalog, the others stay "gift".
+	catalog := newGiftCatalog(&GiftInfo{}, time.Now())
+	catalog.byID[5269] = Gift{ID: 5269, Name: "TikTok"}
+	named := text.nameGifts(catalog.name)
+	assert.Equal(t, "alice sent Rose, TikTok and gift", named.Text)
+	assert.Equal(t, TextSegment{Type: TextGift, Text: "TikTok", GiftID: 5269}, named.Segments[4])
+	assert.Equal(t, "alice sent Rose, gift and gift", text.Text, "the original is left alone")
+
+	// A live completes the events from its catalog.
+	tiktok := newTestTikTok(t)
+	tiktok.giftCatalogTTL = time.Hour
+	live := newTestLive(t, tiktok)
+	live.Info = &RoomInfo{}
+	live.gifts = catalog
+	e := live.enrichEvent(RoomEvent{Message: text.Text, DisplayText: text})
+	assert.Equal(t, "alice sent Rose, TikTok and gift", e.(RoomEvent).Message)
+}
+
+func TestParseDisplayText(t *testing.T) {
+	share := &pb.Text{
+		Key:            "pm_mt_guidance_share",
+		DefaultPattern: "{0:user} shared the LIVE",
+		PiecesList: []*pb.Text_TextPiece{
+			{TextPieceType: &pb.Text_TextPiece_UserValue{UserValue: &pb.Text_TextPieceUser{User: &pb.User{Nickname: "bob"}}}},
+		},
+	}
+	e := parseTestMsg(t, &pb.WebcastSocialMessage{Common: &pb.Common{MsgId: 7900000000000000001, DisplayText: share}})
+	require.IsType(t, UserEvent{}, e)
+	assert.Equal(t, "bob shared the LIVE", e.(UserEvent).DisplayText.Text)
+
+	e = parseTestMsg(t, &pb.WebcastLikeMessage{Common: &pb.Common{MsgId: 7900000000000000002, DisplayText: &pb.Text{
+		DefaultPattern: "{0:user} liked the LIVE",
+		PiecesList: []*pb.Text_TextPiece{
+			{TextPieceType: &pb.Text_TextPiece_UserValue{UserValue: &pb.Text_TextPieceUser{User: &pb.User{Nickname: "carol"}}}},
+		},
+	}}})
+	require.IsType(t, LikeEvent{}, e)
+	assert.Equal(t, "carol liked the LIVE", e.(LikeEvent).Label)
+
+	e = parseTestMsg(t, &pb.WebcastRoomMessage{Common: &pb.Common{MsgId: 7900000000000000003, DisplayText: &pb.Text{
+		DefaultPattern: "Welcome to the LIVE!",
+	}}})
+	require.IsType(t, RoomEvent{}, e)
+	assert.Equal(t, "Welcome to the LIVE!", e.(RoomEvent).Message)
+}
@@ -0,0 +1,332 @@
+package gotiktoklive
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// goalWithSubGoal returns a goal with one sub goal for a gift. Status and sub goals are not part of the generated proto,
+// they are encoded by hand.
+func goalWithSubGoal(progress, target int) *pb.Goal {
+	var gift []byte
+	gift = protowire.AppendTag(gift, 1, protowire.BytesType)
+	gift = protowire.AppendString(gift, "Rose")
+	gift = protowire.AppendTag(gift, 3, protowire.VarintType)
+	gift = protowire.AppendVarint(gift, 1)
+	var subGoal []byte
+	subGoal = protowire.AppendTag(subGoal, 2, protowire.VarintType)
+	subGoal = protowire.AppendVarint(subGoal, 99)
+	subGoal = protowire.AppendTag(subGoal, 3, protowire.VarintType)
+	subGoal = protowire.AppendVarint(subGoal, uint64(progress))
+	subGoal = protowire.AppendTag(subGoal, 4, protowire.VarintType)
+	subGoal = protowire.AppendVarint(subGoal, uint64(target))
+	subGoal = protowire.AppendTag(subGoal, 7, protowire.BytesType)
+	subGoal = protowire.AppendBytes(subGoal, gift)
+	var unknown []byte
+	unknown = protowire.AppendTag(unknown, 3, protowire.VarintType)
+	unknown = protowire.AppendVarint(unknown, 1)
+	unknown = protowire.AppendTag(unknown, 4, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, subGoal)
+
+	goal := &pb.Goal{
+		Id:          5,
+		Description: "100 roses",
+		StartTime:   1000,
+		Stats:       &pb.Goal_GoalStats{TotalCoins: int64(progress), TotalContributor: 2},
+		ContributorsList: []*pb.Goal_GoalContributor{
+			{UserId: 1, DisplayId: "fan", Score: 30},
+		},
+	}
+	goal.ProtoReflect().SetUnknown(unknown)
+	return goal
+}
+
+func TestParseEvents(t *testing.T) {
+	fan := &pb.User{Id: 5, Nickname: "fan"}
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want Event
+	}{
+		{
+			name: "subscribe new",
+			msg: &pb.WebcastSubNotifyMessage{
+				Common:   &pb.Common{MsgId: 7200000000000000001, CreateTime: 1000},
+				User:     fan,
+				SubMonth: 1,
+			},
+			want: SubscribeEvent{MessageID: 7200000000000000001, Timestamp: 1000, User: toUser(fan), Months: 1, Kind: SubscribeNew},
+		},
+		{
+			name: "subscribe renewal",
+			msg: &pb.WebcastSubNotifyMessage{
+				Common:             &pb.Common{MsgId: 7200000000000000002},
+				SubMonth:           3,
+				OldSubscribeStatus: pb.OldSubscribeStatus_OLDSUBSCRIBESTATUS_RESUB,
+				SubscribingStatus:  pb.SubscribingStatus_SUBSCRIBINGSTATUS_CIRCLE,
+			},
+			want: SubscribeEvent{
+				MessageID: 7200000000000000002,
+				User:      &User{},
+				Months:    3,
+				Kind:      SubscribeRenewal,
+				OldStatus: pb.OldSubscribeStatus_OLDSUBSCRIBESTATUS_RESUB,
+				Status:    pb.SubscribingStatus_SUBSCRIBINGSTATUS_CIRCLE,
+			},
+		},
+		{
+			name: "subscribe gifted",
+			msg:  &pb.WebcastSubNotifyMessage{Common: &pb.Common{MsgId: 7200000000000000003}, SubMonth: 1, IsSend: true},
+			want: SubscribeEvent{MessageID: 7200000000000000003, User: &User{}, Months: 1, Kind: SubscribeGifted, IsGift: true},
+		},
+		{
+			name: "goal update",
+			msg: &pb.WebcastGoalUpdateMessage{
+				Common:               &pb.Common{MsgId: 7300000000000000001},
+				Goal:                 goalWithSubGoal(40, 100),
+				ContributorId:        1,
+				ContributorDisplayId: "fan",
+				ContributeCount:      10,
+				ContributeScore:      10,
+			},
+			want: GoalUpdateEvent{
+				MessageID: 7300000000000000001,
+				Goal: Goal{
+					ID:              5,
+					Description:     "100 roses",
+					Status:          1,
+					StartTime:       1000,
+					TotalCoins:      40,
+					Contributors:    2,
+					TopContributors: []GoalContributor{{UserID: 1, Username: "fan", Score: 30}},
+					SubGoals:        []SubGoal{{ID: 99, Progress: 40, Target: 100, GiftName: "Rose", GiftDiamonds: 1}},
+				},
+				ContributorID:   1,
+				ContributorName: "fan",
+				ContributeCount: 10,
+				ContributeScore: 10,
+			},
+		},
+		{
+			name: "envelope",
+			msg: &pb.WebcastEnvelopeMessage{
+				Common:  &pb.Common{MsgId: 7500000000000000101},
+				Display: pb.EnvelopeDisplay_EnvelopeDisplayNew,
+				EnvelopeInfo: &pb.WebcastEnvelopeMessage_EnvelopeInfo{
+					EnvelopeId:   "chest-1",
+					BusinessType: pb.EnvelopeBusinessType_BusinessTypeUserDiamond,
+					SendUserName: "generous",
+					SendUserId:   "123",
+					DiamondCount: 500,
+					PeopleCount:  10,
+					CreateAt:     "1700000000",
+					UnpackAt:     60,
+				},
+			},
+			want: EnvelopeEvent{
+				MessageID:    7500000000000000101,
+				EnvelopeID:   "chest-1",
+				BusinessType: pb.EnvelopeBusinessType_BusinessTypeUserDiamond,
+				Display:      pb.EnvelopeDisplay_EnvelopeDisplayNew,
+				Sender:       &User{ID: 123, Nickname: "generous", ExtraAttributes: &ExtraAttributes{}},
+				Diamonds:     500,
+				People:       10,
+				CreatedAt:    time.Unix(1700000000, 0),
+				OpenAt:       time.Unix(1700000060, 0),
+			},
+		},
+		{
+			name: "emote chat",
+			msg: &pb.WebcastEmoteChatMessage{
+				Common: &pb.Common{MsgId: 7600000000000000001, CreateTime: 1000},
+				User:   fan,
+				EmoteList: []*pb.Emote{
+					{EmoteId: "e1", Image: &pb.Image{UrlList: []string{"https://example.com/e1.webp"}}, EmoteType: pb.EmoteType_EMOTETYPEWITHSTICKER},
+				},
+			},
+			want: EmoteChatEvent{
+				MessageID: 7600000000000000001,
+				Timestamp: 1000,
+				User:      toUser(fan),
+				Emotes: []Emote{{
+					ID:    "e1",
+					Image: &ProfilePicture{Urls: []string{"https://example.com/e1.webp"}},
+					Type:  pb.EmoteType_EMOTETYPEWITHSTICKER,
+				}},
+			},
+		},
+		{
+			name: "barrage",
+			msg: &pb.WebcastBarrageMessage{
+				Common:         &pb.Common{MsgId: 7600000000000000002},
+				MsgType:        pb.WebcastBarrageMessage_FANSLEVELUPGRADE,
+				Content:        &pb.Text{DefaultPattern: "{0:user} reached level 10"},
+				Duration:       3000,
+				FansLevelParam: &pb.WebcastBarrageMessage_BarrageTypeFansLevelParam{CurrentGrade: 10, User: fan},
+			},
+			want: BarrageEvent{
+				MessageID: 7600000000000000002,
+				Type:      pb.WebcastBarrageMessage_FANSLEVELUPGRADE,
+				Content:   "{0:user} reached level 10",
+				Duration:  3000,
+				User:      toUser(fan),
+				Level:     10,
+			},
+		},
+		{
+			name: "rank update",
+			msg: &pb.WebcastRankUpdateMessage{
+				Common: &pb.Common{MsgId: 7600000000000000003},
+				UpdatesList: []*pb.WebcastRankUpdateMessage_RankUpdate{
+					{RankType: 8, OwnerRank: 3, Owneronrank: true, DefaultContent: &pb.Text{DefaultPattern: "No. 3"}},
+				},
+				TabsList: []*pb.WebcastRankUpdateMessage_RankTabInfo{{RankType: 8, Title: "Hourly"}},
+			},
+			want: RankUpdateEvent{
+				MessageID: 7600000000000000003,
+				Updates:   []RankUpdate{{RankType: 8, Rank: 3, OnRank: true, Text: "No. 3"}},
+				Tabs:      []RankTab{{RankType: 8, Title: "Hourly"}},
+			},
+		},
+		{
+			name: "rank text",
+			msg: &pb.WebcastRankTextMessage{
+				Common:               &pb.Common{MsgId: 7600000000000000004},
+				OwnerIdxBeforeUpdate: 5,
+				OwnerIdxAfterUpdate:  2,
+				OtherGetBadgeMsg:     &pb.Text{DefaultPattern: "moved up to No. 2"},
+			},
+			want: RankTextEvent{MessageID: 7600000000000000004, RankBefore: 5, Rank: 2, Text: "moved up to No. 2"},
+		},
+		{
+			name: "hourly rank",
+			msg: &pb.WebcastHourlyRankMessage{
+				Common: &pb.Common{MsgId: 7600000000000000005},
+				Data: &pb.WebcastHourlyRankMessage_RankContainer{
+					Rankings: &pb.Ranking{Type: "hourly", Label: "Top 10", Details: []*pb.ValueLabel{{Label: "Gaming"}, {}}},
+				},
+			},
+			want: HourlyRankEvent{
+				MessageID: 7600000000000000005,
+				Rankings:  []Ranking{{Type: "hourly", Label: "Top 10", Details: []string{"Gaming"}}},
+			},
+		},
+		{
+			name: "shopping pin",
+			msg: &pb.WebcastOecLiveShoppingMessage{
+				Common: &pb.Common{MsgId: 7800000000000000001, CreateTime: 1000},
+				Data1:  2,
+				ShopData: &pb.WebcastOecLiveShoppingMessage_LiveShoppingData{
+					Title:       "Mug",
+					PriceString: "$12.99",
+					ImageUrl:    "https://example.com/mug.jpg",
+					ShopName:    "Shopify",
+				},
+				ShopTimings: &pb.TimeStampContainer{Timestamp1: 1700000000, Timestamp2: 1700000300},
+				Details:     &pb.WebcastOecLiveShoppingMessage_LiveShoppingDetails{Id1: "shop-1"},
+			},
+			want: ShoppingEvent{
+				MessageID: 7800000000000000001,
+				Timestamp: 1000,
+				Action:    ShoppingPin,
+				Type:      2,
+				ShopID:    "shop-1",
+				ShopName:  "Shopify",
+				Title:     "Mug",
+				Price:     "$12.99",
+				ImageURL:  "https://example.com/mug.jpg",
+				StartTime: 1700000000,
+				EndTime:   1700000300,
+			},
+		},
+		{
+			name: "shopping unpin",
+			msg:  &pb.WebcastOecLiveShoppingMessage{Common: &pb.Common{MsgId: 7800000000000000002}},
+			want: ShoppingEvent{MessageID: 7800000000000000002, Action: ShoppingUnpin},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseTestMsg(t, tt.msg))
+		})
+	}
+}
+
+func TestEnvelopeOpenAt(t *testing.T) {
+	assert.Equal(t, time.Unix(1700000100, 0), envelopeOpenAt(time.Unix(1700000000, 0), 1700000100))
+	assert.True(t, envelopeOpenAt(time.Time{}, 0).IsZero())
+}
+
+func TestParseUserDetails(t *testing.T) {
+	e := parseTestMsg(t, &pb.WebcastChatMessage{
+		Common:  &pb.Common{MsgId: 7900000000000000101},
+		Content: "hi",
+		User: &pb.User{
+			Id:         8,
+			Nickname:   "fan",
+			Verified:   true,
+			FollowInfo: &pb.User_FollowInfo{FollowingCount: 12, FollowerCount: 3400},
+			PayGrade:   &pb.User_PayGrade{Level: 25, Name: "Lv.25"},
+			FansClub: &pb.User_FansClub{Data: &pb.User_FansClub_FansClubData{
+				ClubName:           "Tacos",
+				Level:              7,
+				UserFansClubStatus: pb.User_FansClub_FansClubData_ACTIVE,
+			}},
+			FansClubInfo:  &pb.User_FansClubInfo{FansScore: 900},
+			SubscribeInfo: &pb.User_SubscribeInfo{IsSubscribe: true, Badge: &pb.User_SubscribeBadge{OriginImg: &pb.Image{UrlList: []string{"https://example.com/sub.png"}}}},
+			BadgeList: []*pb.BadgeStruct{
+				{DisplayType: pb.BadgeStruct_BADGEDISPLAYTYPE_IMAGE, BadgeType: &pb.BadgeStruct_Image{Image: &pb.BadgeStruct_ImageBadge{Image: &pb.Image{UrlList: []string{"https://example.com/badge.png"}}}}},
+				{DisplayType: pb.BadgeStruct_BADGEDISPLAYTYPE_COMBINE, BadgeType: &pb.BadgeStruct_Combine{Combine: &pb.BadgeStruct_CombineBadge{Str: "Moderator"}}},
+			},
+		},
+	})
+	require.IsType(t, ChatEvent{}, e)
+	user := e.(ChatEvent).User
+	assert.True(t, user.Verified)
+	assert.Equal(t, &FollowInfo{Following: 12, Followers: 3400}, user.FollowInfo)
+	assert.Equal(t, 25, user.PayGrade.Level)
+	assert.Equal(t, &FanClub{Name: "Tacos", Level: 7, Score: 900, Active: true}, user.FanClub)
+	assert.True(t, user.Subscriber.IsSubscriber)
+	assert.Equal(t, []string{"https://example.com/sub.png"}, user.Subscriber.Badge.Urls)
+	require.Len(t, user.Badge.Badges, 2)
+	assert.Equal(t, []string{"https://example.com/badge.png"}, user.Badge.Badges[0].Image.Urls)
+	assert.Equal(t, "Moderator", user.Badge.Badges[1].Text)
+
+	assert.Nil(t, toUser(&pb.User{Id: 1}).FanClub)
+}
+
+func TestUserIdentity(t *testing.T) {
+	for _, m := range []proto.Message{
+		&pb.WebcastChatMessage{Common: &pb.Common{MsgId: 7900000000000000201}, User: &pb.User{Id: 42, DisplayId: "handle", SecUid: "MS4w", Nickname: "Nick"}},
+		&pb.WebcastGiftMessage{Common: &pb.Common{MsgId: 7900000000000000202}, GiftId: 1, Gift: &pb.GiftStruct{}, UserGiftReciever: &pb.WebcastGiftMessage_UserGiftReciever{}, User: &pb.User{IdStr: "42", DisplayId: "handle", SecUid: "MS4w", Nickname: "Other"}},
+		&pb.WebcastMemberMessage{Common: &pb.Common{MsgId: 7900000000000000203}, User: &pb.User{Id: 42, DisplayId: "handle", SecUid: "MS4w"}},
+	} {
+		e := parseTestMsg(t, m)
+		var user *User
+		switch e := e.(type) {
+		case ChatEvent:
+			user = e.User
+		case GiftEvent:
+			user = e.User
+		case UserEvent:
+			user = e.User
+		default:
+			t.Fatalf("unexpected event %T", e)
+		}
+		assert.Equal(t, int64(42), user.ID)
+		assert.Equal(t, "handle", user.Username)
+		assert.Equal(t, "MS4w", user.SecUID)
+		assert.Equal(t, "42", user.Key())
+	}
+
+	assert.Equal(t, "sec:MS4w", (&User{SecUID: "MS4w", Username: "handle"}).Key())
+	assert.Equal(t, "@handle", (&User{Username: "handle"}).Key())
+	assert.Equal(t, "", (&User{Nickname: "Nick"}).Key())
+	assert.Equal(t, "", (*User)(nil).Key())
+}
@@ -1,6 +1,7 @@
 package gotiktoklive
 
 import (
+	"context"
 	"encoding/json"
 	"strconv"
 )
@@ -31,6 +32,11 @@ func (t *TikTok) NewFeed() *Feed {
 //
 //	to the Feed.LiveStreams list.
 func (f *Feed) Next() (*FeedItem, error) {
+	return f.NextContext(context.Background())
+}
+
+// NextContext is like Next but the request is bound to ctx.
+func (f *Feed) NextContext(ctx context.Context) (*FeedItem, error) {
 	if !f.HasMore {
 		return nil, ErrNoMoreFeedItems
 	}
@@ -42,7 +48,7 @@ func (f *Feed) Next() (*FeedItem, error) {
 		params["max_time"] = strconv.FormatInt(f.maxTime, 10)
 	}
 
-	body, _, err := f.t.sendRequest(&reqOptions{
+	body, _, err := f.t.sendRequest(ctx, &reqOptions{
 		Endpoint: urlFeed,
 		Query:    params,
 	}, nil)
@@ -70,3 +76,8 @@ func (f *Feed) Next() (*FeedItem, error) {
 func (s *LiveStream) Track() (*Live, error) {
 	return s.t.TrackRoom(s.Rid)
 }
+
+// TrackContext is like Track but connecting to the room is bound to ctx.
+func (s *LiveStream) TrackContext(ctx context.Context) (*Live, error) {
+	return s.t.TrackRoomContext(ctx, s.Rid)
+}
@@ -0,0 +1,52 @@
+package gotiktoklive_test
+
+import (
+	"testing"
+
+	"github.com/steampoweredtaco/gotiktoklive"
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/steampoweredtaco/gotiktoklive/tiktoktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGiftCatalog(t *testing.T) {
+	srv := tiktoktest.NewServer()
+	defer srv.Close()
+	room := srv.AddRoom("tester", "7000000000000000002")
+	rose := gotiktoklive.Gift{
+		ID:       5655,
+		Name:     "Rose",
+		Diamonds: 1,
+		Combo:    true,
+		Icon:     &gotiktoklive.ProfilePicture{Urls: []string{"https://example.com/rose.webp"}},
+	}
+	room.SetGifts(rose)
+
+	// Tracking the room does not fetch the catalog, the first sparse gift does.
+	tiktok := newServerTikTok(t, srv)
+	live, err := tiktok.TrackUser("tester")
+	require.NoError(t, err)
+	assert.Zero(t, room.GiftFetches())
+
+	// Gift messages without the gift details are completed from the catalog.
+	require.NoError(t, room.Push(&pb.WebcastGiftMessage{
+		Common:           &pb.Common{MsgId: 8000000000000000011},
+		GiftId:           5655,
+		RepeatCount:      3,
+		User:             &pb.User{Id: 1, Nickname: "sender"},
+		UserGiftReciever: &pb.WebcastGiftMessage_UserGiftReciever{},
+	}))
+	gift := nextEvent[gotiktoklive.GiftEvent](t, live)
+	assert.Equal(t, "Rose", gift.Name)
+	assert.Equal(t, 1, gift.Diamonds)
+	assert.True(t, gift.IsComboGift)
+	assert.Equal(t, rose.Icon, gift.Icon)
+	assert.Equal(t, 1, room.GiftFetches())
+
+	// The catalog is cached once fetched.
+	gifts, err := tiktok.GetGiftCatalog(room.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []gotiktoklive.Gift{rose}, gifts)
+	assert.Equal(t, 1, room.GiftFetches())
+}
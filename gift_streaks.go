@@ -0,0 +1,170 @@
+package gotiktoklive
+
+import (
+	"sync"
+	"time"
+)
+
+type giftStreakKey struct {
+	userID  int64
+	giftID  int64
+	groupID int64
+}
+
+type giftStreak struct {
+	last     GiftEvent
+	base     int
+	count    int
+	started  int64
+	lastSeen time.Time
+}
+
+type endedGiftStreak struct {
+	count int
+	at    time.Time
+	// final is set if the streak ended with the RepeatEnd message, rather than timing out.
+	final bool
+}
+
+// giftStreakTracker follows combo gifts per user, gift and combo group so every gift of a streak is counted once.
+type giftStreakTracker struct {
+	timeout time.Duration
+	mu      sync.Mutex
+	active  map[giftStreakKey]*giftStreak
+	// ended remembers the final count of recent combo streaks. TikTok may still send updates for a streak after it
+	// timed out, and repeats the last message of a streak.
+	ended map[giftStreakKey]endedGiftStreak
+}
+
+func newGiftStreakTracker(timeout time.Duration) *giftStreakTracker {
+	return &giftStreakTracker{
+		timeout: timeout,
+		active:  make(map[giftStreakKey]*giftStreak),
+		ended:   make(map[giftStreakKey]endedGiftStreak),
+	}
+}
+
+func (g *giftStreakTracker) process(e Event) []Event {
+	gift, ok := e.(GiftEvent)
+	if !ok {
+		return nil
+	}
+	var userID int64
+	if gift.User != nil {
+		userID = gift.User.ID
+	}
+	key := giftStreakKey{userID: userID, giftID: gift.ID, groupID: gift.GroupID}
+	count := max(gift.RepeatCount, 1)
+
+	streakable := gift.Type == 1 || gift.IsComboGift
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !streakable {
+		// Every gift that cannot be comboed is a streak of its own.
+		return []Event{g.end(key, &giftStreak{last: gift, count: count, started: gift.Timestamp}, false)}
+	}
+	s, ok := g.active[key]
+	if !ok {
+		base := 0
+		if ended, ok := g.ended[key]; ok {
+			switch {
+			case ended.final && gift.RepeatEnd && count == ended.count:
+				// The last message of the streak, repeated.
+				return nil
+			case ended.final:
+				// A new combo with the same gift, it counts from the start again.
+			case count <= ended.count:
+				// A late update of a streak that timed out, already reported.
+				return nil
+			default:
+				base = ended.count
+			}
+			delete(g.ended, key)
+		}
+		s = &giftStreak{base: base, started: gift.Timestamp}
+		g.active[key] = s
+	}
+	if count < s.count {
+		// Out of order update.
+		return nil
+	}
+	updated := count > s.count
+	s.last = gift
+	s.count = count
+	s.lastSeen = time.Now()
+
+	if gift.RepeatEnd {
+		return []Event{g.end(key, s, false)}
+	}
+	if !updated {
+		return nil
+	}
+	return []Event{GiftStreakUpdateEvent{
+		Gift:      gift,
+		Count:     s.count - s.base,
+		Diamonds:  gift.Diamonds * (s.count - s.base),
+		StartedAt: s.started,
+		eventMeta: eventMeta{isHistory: gift.isHistory},
+	}}
+}
+
+// expire ends all streaks that have not been updated since the timeout, or all of them if force is set.
+func (g *giftStreakTracker) expire(now time.Time, force bool) []Event {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var out []Event
+	for key, s := range g.active {
+		if force || now.Sub(s.lastSeen) >= g.timeout {
+			out = append(out, g.end(key, s, true))
+		}
+	}
+	for key, ended := range g.ended {
+		if now.Sub(ended.at) >= 5*g.timeout {
+			delete(g.ended, key)
+		}
+	}
+	return out
+}
+
+func (g *giftStreakTracker) end(key giftStreakKey, s *giftStreak, timedOut bool) GiftStreakEndEvent {
+	if _, ok := g.active[key]; ok {
+		delete(g.active, key)
+		g.ended[key] = endedGiftStreak{count: s.count, at: time.Now(), final: !timedOut}
+	}
+	return GiftStreakEndEvent{
+		Gift:      s.last,
+		Count:     s.count - s.base,
+		Diamonds:  s.last.Diamonds * (s.count - s.base),
+		StartedAt: s.started,
+		TimedOut:  timedOut,
+		eventMeta: eventMeta{isHistory: s.last.isHistory},
+	}
+}
+
+// expireGiftStreaks periodically ends streaks that timed out until the live is done.
+func (l *Live) expireGiftStreaks() {
+	ticker := time.NewTicker(max(l.giftStreaks.timeout/4, 100*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.done():
+			return
+		case now := <-ticker.C:
+			for _, e := range l.giftStreaks.expire(now, false) {
+				l.emit(e)
+			}
+		}
+	}
+}
+
+// endGiftStreaks ends all running streaks, used when the live is over so no streak goes unreported.
+func (l *Live) endGiftStreaks() {
+	if l.giftStreaks == nil {
+		return
+	}
+	for _, e := range l.giftStreaks.expire(time.Now(), true) {
+		l.emit(e)
+	}
+}
@@ -0,0 +1,97 @@
+package gotiktoklive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGiftStreaks(t *testing.T) {
+	tiktok := newTestTikTok(t)
+	tiktok.giftStreakTimeout = time.Hour
+	live := newTestLive(t, tiktok)
+
+	user := &User{ID: 42}
+	rose := GiftEvent{ID: 5655, Diamonds: 1, Type: 1, GroupID: 7, User: user}
+	for _, count := range []int{1, 2, 2, 5} {
+		g := rose
+		g.RepeatCount = count
+		live.emit(g)
+	}
+	g := rose
+	g.RepeatCount = 6
+	g.RepeatEnd = true
+	live.emit(g)
+	// TikTok repeats the final gift of a streak, it must not start a new one.
+	live.emit(g)
+
+	// Gifts that cannot be comboed end right away.
+	live.emit(GiftEvent{ID: 1, Diamonds: 100, Type: 2, RepeatCount: 1, User: user})
+
+	var updates []int
+	var ends []GiftStreakEndEvent
+	for len(live.Events) > 0 {
+		switch e := (<-live.Events).(type) {
+		case GiftStreakUpdateEvent:
+			updates = append(updates, e.Count)
+		case GiftStreakEndEvent:
+			ends = append(ends, e)
+		}
+	}
+	assert.Equal(t, []int{1, 2, 5}, updates)
+	if assert.Len(t, ends, 2) {
+		assert.Equal(t, 6, ends[0].Count)
+		assert.Equal(t, 6, ends[0].Diamonds)
+		assert.False(t, ends[0].TimedOut)
+		assert.Equal(t, 100, ends[1].Diamonds)
+	}
+}
+
+func TestGiftStreaksRepeatedGifts(t *testing.T) {
+	g := newGiftStreakTracker(time.Hour)
+	user := &User{ID: 42}
+
+	// The same gift that cannot be comboed, sent twice, is two gifts.
+	lion := GiftEvent{ID: 1, Diamonds: 100, Type: 2, RepeatCount: 1, User: user}
+	var ends []GiftStreakEndEvent
+	for i := 0; i < 2; i++ {
+		out := g.process(lion)
+		if assert.Len(t, out, 1) {
+			ends = append(ends, out[0].(GiftStreakEndEvent))
+		}
+	}
+	assert.Len(t, ends, 2)
+
+	// A new combo of the same gift after a streak ended is reported on its own.
+	rose := GiftEvent{ID: 5655, Diamonds: 1, Type: 1, GroupID: 7, User: user, RepeatCount: 3, RepeatEnd: true}
+	assert.Len(t, g.process(rose), 1)
+	assert.Empty(t, g.process(rose), "the repeated last message of the streak")
+	rose.RepeatCount, rose.RepeatEnd = 1, false
+	assert.Len(t, g.process(rose), 1)
+	rose.RepeatCount, rose.RepeatEnd = 2, true
+	out := g.process(rose)
+	if assert.Len(t, out, 1) {
+		assert.Equal(t, 2, out[0].(GiftStreakEndEvent).Count)
+	}
+}
+
+func TestGiftStreaksTimeout(t *testing.T) {
+	g := newGiftStreakTracker(time.Second)
+	g.process(GiftEvent{ID: 1, Diamonds: 5, Type: 1, RepeatCount: 3})
+
+	now := time.Now()
+	assert.Empty(t, g.expire(now, false))
+	out := g.expire(now.Add(2*time.Second), false)
+	if assert.Len(t, out, 1) {
+		end := out[0].(GiftStreakEndEvent)
+		assert.True(t, end.TimedOut)
+		assert.Equal(t, 15, end.Diamonds)
+	}
+
+	// A late update continues counting from where the timed out streak ended.
+	out = g.process(GiftEvent{ID: 1, Diamonds: 5, Type: 1, RepeatCount: 4, RepeatEnd: true})
+	if assert.Len(t, out, 1) {
+		assert.Equal(t, 1, out[0].(GiftStreakEndEvent).Count)
+	}
+}
@@ -0,0 +1,254 @@
+package gotiktoklive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Gift is an entry of the gift catalog of a room, see TikTok.GetGiftCatalog. Combo gifts can be sent as a streak.
+type Gift struct {
+	ID       int64
+	Name     string
+	Describe string
+	Diamonds int
+	Combo    bool
+	Type     int
+	Region   string
+	Icon     *ProfilePicture
+	Image    *ProfilePicture
+}
+
+// giftCatalogRetry is how long a live waits before fetching the gift catalog again after a failed fetch.
+const giftCatalogRetry = time.Minute
+
+// giftCatalogKey identifies a cached gift catalog. The gifts of a room depend on the region TikTok serves it in.
+type giftCatalogKey struct {
+	room   string
+	region string
+}
+
+// giftCatalog is the cached gift catalog of a room.
+type giftCatalog struct {
+	info    *GiftInfo
+	gifts   []Gift
+	byID    map[int64]Gift
+	fetched time.Time
+}
+
+func newGiftCatalog(info *GiftInfo, fetched time.Time) *giftCatalog {
+	c := &giftCatalog{
+		info:    info,
+		byID:    make(map[int64]Gift),
+		fetched: fetched,
+	}
+	if info == nil {
+		return c
+	}
+	for _, g := range info.Gifts {
+		gift := Gift{
+			ID:       int64(g.ID),
+			Name:     g.Name,
+			Describe: g.Describe,
+			Diamonds: g.DiamondCount,
+			Combo:    g.Combo,
+			Type:     g.Type,
+			Region:   g.Region,
+		}
+		if len(g.Icon.URLList) > 0 {
+			gift.Icon = &ProfilePicture{Urls: g.Icon.URLList}
+		}
+		if len(g.Image.URLList) > 0 {
+			gift.Image = &ProfilePicture{Urls: g.Image.URLList}
+		}
+		c.gifts = append(c.gifts, gift)
+		c.byID[gift.ID] = gift
+	}
+	return c
+}
+
+// sparseGift reports whether the gift message left out details the catalog can fill in.
+func sparseGift(e GiftEvent) bool {
+	return e.Name == "" || e.Diamonds == 0 || e.Icon == nil || e.Image == nil
+}
+
+// enrich fills in what the gift message left out from the catalog entry of the gift.
+func (c *giftCatalog) enrich(e GiftEvent) GiftEvent {
+	gift, ok := c.byID[e.ID]
+	if !ok {
+		return e
+	}
+	if e.Name == "" {
+		e.Name = gift.Name
+	}
+	if e.Describe == "" {
+		e.Describe = gift.Describe
+	}
+	if e.Diamonds == 0 {
+		e.Diamonds = gift.Diamonds
+	}
+	if e.Type == 0 {
+		e.Type = gift.Type
+	}
+	if e.Icon == nil {
+		e.Icon = gift.Icon
+	}
+	if e.Image == nil {
+		e.Image = gift.Image
+	}
+	e.IsComboGift = e.IsComboGift || gift.Combo
+	return e
+}
+
+// name returns the name of the gift, or "" if it is not in the catalog.
+func (c *giftCatalog) name(id int) string {
+	return c.byID[int64(id)].Name
+}
+
+// enrichEvent completes the event from the gift catalog of the live: sparse gift events and display texts with gifts
+// TikTok sent without a name. The catalog is only fetched once one of them is seen.
+func (l *Live) enrichEvent(e Event) Event {
+	switch v := e.(type) {
+	case GiftEvent:
+		if !sparseGift(v) {
+			return e
+		}
+		if catalog := l.giftCatalog(); catalog != nil {
+			return catalog.enrich(v)
+		}
+	case RoomEvent:
+		if !v.DisplayText.hasUnnamedGifts() {
+			return e
+		}
+		if catalog := l.giftCatalog(); catalog != nil {
+			renamed := v.Message == v.DisplayText.Text
+			v.DisplayText = v.DisplayText.nameGifts(catalog.name)
+			if renamed {
+				v.Message = v.DisplayText.Text
+			}
+			return v
+		}
+	case UserEvent:
+		if !v.DisplayText.hasUnnamedGifts() {
+			return e
+		}
+		if catalog := l.giftCatalog(); catalog != nil {
+			v.DisplayText = v.DisplayText.nameGifts(catalog.name)
+			return v
+		}
+	case LikeEvent:
+		if !v.DisplayText.hasUnnamedGifts() {
+			return e
+		}
+		if catalog := l.giftCatalog(); catalog != nil {
+			v.DisplayText = v.DisplayText.nameGifts(catalog.name)
+			v.Label = v.DisplayText.Text
+			return v
+		}
+	}
+	return e
+}
+
+// GetGiftCatalog returns the gifts that can be sent in the room. The catalog is cached per room and region, see
+// GiftCatalogTTL.
+func (t *TikTok) GetGiftCatalog(roomID string) ([]Gift, error) {
+	return t.GetGiftCatalogContext(context.Background(), roomID)
+}
+
+// GetGiftCatalogContext is like GetGiftCatalog but the request is bound to ctx.
+func (t *TikTok) GetGiftCatalogContext(ctx context.Context, roomID string) ([]Gift, error) {
+	catalog, err := t.giftCatalog(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(catalog.gifts), nil
+}
+
+// giftCatalog returns the cached catalog of the room, fetching it if it is missing or expired.
+func (t *TikTok) giftCatalog(ctx context.Context, roomID string) (*giftCatalog, error) {
+	key := giftCatalogKey{room: roomID, region: t.giftRegion()}
+	t.mu.Lock()
+	catalog, ok := t.giftCatalogs[key]
+	t.mu.Unlock()
+	if ok && time.Since(catalog.fetched) < t.giftCatalogTTL {
+		return catalog, nil
+	}
+
+	info, err := t.getGiftInfo(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	catalog = newGiftCatalog(info, time.Now())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.giftCatalogs == nil {
+		t.giftCatalogs = make(map[giftCatalogKey]*giftCatalog)
+	}
+	for k, c := range t.giftCatalogs {
+		if time.Since(c.fetched) >= t.giftCatalogTTL {
+			delete(t.giftCatalogs, k)
+		}
+	}
+	if t.giftCatalogTTL > 0 {
+		t.giftCatalogs[key] = catalog
+	}
+	return catalog, nil
+}
+
+// giftRegion returns the region gift catalogs are fetched in. TikTok picks it from the language of the requests and
+// the IP they come from, so from the proxy in use.
+func (t *TikTok) giftRegion() string {
+	region := defaultGETParams["app_language"]
+	if t.proxy != nil {
+		region += "|" + t.proxy.Host
+	}
+	return region
+}
+
+// giftCatalog returns the gift catalog of the room, fetching it on first use and again once it expired. Lives that
+// were not fetched from TikTok, such as replays, have no catalog. After a failed fetch the live goes without a
+// catalog for giftCatalogRetry.
+func (l *Live) giftCatalog() *giftCatalog {
+	if l.Info == nil {
+		return nil
+	}
+	l.giftsMu.Lock()
+	defer l.giftsMu.Unlock()
+	if l.gifts != nil && time.Since(l.gifts.fetched) < l.t.giftCatalogTTL {
+		return l.gifts
+	}
+	if time.Since(l.giftsFailed) < giftCatalogRetry {
+		return l.gifts
+	}
+	catalog, err := l.t.giftCatalog(l.ctx, l.ID)
+	if err != nil {
+		// The gift catalog only enriches gift events, the live works without it.
+		l.t.warnHandler(fmt.Errorf("cannot fetch gift catalog: %w", err))
+		l.giftsFailed = time.Now()
+		return l.gifts
+	}
+	l.gifts = catalog
+	return catalog
+}
+
+func (t *TikTok) getGiftInfo(ctx context.Context, roomID string) (*GiftInfo, error) {
+	params := copyMap(defaultGETParams)
+	params["room_id"] = roomID
+
+	body, _, err := t.sendRequest(ctx, &reqOptions{
+		Endpoint: urlGiftInfo,
+		Query:    params,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsp giftInfoRsp
+	if err := json.Unmarshal(body, &rsp); err != nil {
+		return nil, err
+	}
+	return rsp.GiftInfo, nil
+}
@@ -0,0 +1,59 @@
+package gotiktoklive
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGiftCatalogEnrich(t *testing.T) {
+	var rsp giftInfoRsp
+	require.NoError(t, json.Unmarshal([]byte(`{"data":{"gifts":[
+		{"id":5655,"name":"Rose","describe":"sent Rose","diamond_count":1,"combo":true,"type":1,"region":"US",
+		 "icon":{"url_list":["https://example.com/rose.webp"]},"image":{"url_list":["https://example.com/rose.png"]}},
+		{"id":5269,"name":"TikTok","diamond_count":1}
+	]}}`), &rsp))
+	catalog := newGiftCatalog(rsp.GiftInfo, time.Now())
+	require.Len(t, catalog.gifts, 2)
+	assert.Equal(t, Gift{
+		ID:       5655,
+		Name:     "Rose",
+		Describe: "sent Rose",
+		Diamonds: 1,
+		Combo:    true,
+		Type:     1,
+		Region:   "US",
+		Icon:     &ProfilePicture{Urls: []string{"https://example.com/rose.webp"}},
+		Image:    &ProfilePicture{Urls: []string{"https://example.com/rose.png"}},
+	}, catalog.gifts[0])
+	assert.Nil(t, catalog.gifts[1].Icon)
+
+	sparse := catalog.enrich(GiftEvent{ID: 5655, RepeatCount: 2})
+	assert.Equal(t, "Rose", sparse.Name)
+	assert.Equal(t, 1, sparse.Diamonds)
+	assert.True(t, sparse.IsComboGift)
+	assert.Equal(t, catalog.gifts[0].Icon, sparse.Icon)
+	assert.Equal(t, 2, sparse.RepeatCount)
+
+	// Details sent with the message win over the catalog.
+	full := catalog.enrich(GiftEvent{ID: 5655, Name: "Big Rose", Diamonds: 5})
+	assert.Equal(t, "Big Rose", full.Name)
+	assert.Equal(t, 5, full.Diamonds)
+
+	unknown := GiftEvent{ID: 1, Name: "Unknown"}
+	assert.Equal(t, unknown, catalog.enrich(unknown))
+}
+
+func TestGiftRegion(t *testing.T) {
+	tiktok := newTestTikTok(t)
+	direct := tiktok.giftRegion()
+	assert.Equal(t, "en-US", direct)
+
+	// A proxy can put the requests in another region, which has its own catalog.
+	tiktok.proxy = &url.URL{Scheme: "http", Host: "proxy.example.com:8080"}
+	assert.NotEqual(t, direct, tiktok.giftRegion())
+}
@@ -6,7 +6,7 @@ require (
 	github.com/erni27/imcache v1.2.1
 	github.com/gobwas/ws v1.1.0
 	github.com/stretchr/testify v1.9.0
-	go.uber.org/ratelimit v0.3.1
+	golang.org/x/time v0.5.0
 )
 
 retract (
@@ -15,7 +15,6 @@ retract (
 )
 
 require (
-	github.com/benbjohnson/clock v1.3.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/google/go-cmp v0.6.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
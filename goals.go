@@ -0,0 +1,230 @@
+package gotiktoklive
+
+import (
+	"sort"
+	"sync"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// Goal is a LIVE goal set by the host. Progress is tracked per sub goal, usually one per gift the host asks for.
+type Goal struct {
+	ID              int64
+	Description     string
+	Status          int
+	StartTime       int64
+	ExpireTime      int64
+	FinishTime      int64
+	TotalCoins      int
+	Contributors    int
+	TopContributors []GoalContributor
+	SubGoals        []SubGoal
+}
+
+// SubGoal is a target of a Goal, such as receiving a number of a gift.
+type SubGoal struct {
+	ID           int64
+	Type         int
+	Progress     int
+	Target       int
+	GiftName     string
+	GiftDiamonds int
+	GiftImage    *ProfilePicture
+}
+
+// GoalContributor is one of the top contributors of a Goal.
+type GoalContributor struct {
+	UserID   int64
+	Username string
+	Avatar   *ProfilePicture
+	Score    int
+}
+
+// Progress returns the progress and target summed over all sub goals.
+func (g Goal) Progress() (progress, target int) {
+	for _, s := range g.SubGoals {
+		progress += min(s.Progress, s.Target)
+		target += s.Target
+	}
+	return progress, target
+}
+
+// Done returns true if all sub goals reached their target.
+func (g Goal) Done() bool {
+	if len(g.SubGoals) == 0 {
+		return false
+	}
+	for _, s := range g.SubGoals {
+		if s.Progress < s.Target {
+			return false
+		}
+	}
+	return true
+}
+
+func toGoal(g *pb.Goal) Goal {
+	if g == nil {
+		return Goal{}
+	}
+	goal := Goal{
+		ID:          g.Id,
+		Description: g.Description,
+		StartTime:   g.StartTime,
+		ExpireTime:  g.ExpireTime,
+		FinishTime:  g.RealFinishTime,
+	}
+	if g.Stats != nil {
+		goal.TotalCoins = int(g.Stats.TotalCoins)
+		goal.Contributors = int(g.Stats.TotalContributor)
+	}
+	for _, c := range g.ContributorsList {
+		goal.TopContributors = append(goal.TopContributors, GoalContributor{
+			UserID:   c.UserId,
+			Username: c.DisplayId,
+			Avatar:   toProfilePicture(c.Avatar),
+			Score:    int(c.Score),
+		})
+	}
+	goal.Status, goal.SubGoals = parseGoalUnknown(g.ProtoReflect().GetUnknown())
+	return goal
+}
+
+// parseGoalUnknown decodes the status and sub goals of a goal. They are not part of the generated proto messages yet
+// and end up in the unknown fields:
+//
+//	int32 status = 3;
+//	repeated SubGoal subGoalsList = 4;
+//
+//	message SubGoal {
+//	  int32 type = 1;
+//	  int64 id = 2;
+//	  int64 progress = 3;
+//	  int64 target = 4;
+//	  string idStr = 6;
+//	  SubGoalGift gift = 7;
+//	}
+//
+//	message SubGoalGift {
+//	  string name = 1;
+//	  Image icon = 2;
+//	  int64 diamondCount = 3;
+//	}
+func parseGoalUnknown(b []byte) (status int, subGoals []SubGoal) {
+	walkProtoFields(b, func(num protowire.Number, v uint64, raw []byte) {
+		switch num {
+		case 3:
+			status = int(v)
+		case 4:
+			subGoals = append(subGoals, parseSubGoal(raw))
+		}
+	})
+	return status, subGoals
+}
+
+func parseSubGoal(b []byte) SubGoal {
+	var s SubGoal
+	walkProtoFields(b, func(num protowire.Number, v uint64, raw []byte) {
+		switch num {
+		case 1:
+			s.Type = int(v)
+		case 2:
+			s.ID = int64(v)
+		case 3:
+			s.Progress = int(v)
+		case 4:
+			s.Target = int(v)
+		case 7:
+			walkProtoFields(raw, func(num protowire.Number, v uint64, raw []byte) {
+				switch num {
+				case 1:
+					s.GiftName = string(raw)
+				case 2:
+					var img pb.Image
+					if proto.Unmarshal(raw, &img) == nil {
+						s.GiftImage = toProfilePicture(&img)
+					}
+				case 3:
+					s.GiftDiamonds = int(v)
+				}
+			})
+		}
+	})
+	return s
+}
+
+// walkProtoFields calls f for every varint and length delimited field in b. Other field types are skipped, decoding
+// stops at the first malformed field.
+func walkProtoFields(b []byte, f func(num protowire.Number, v uint64, raw []byte)) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return
+		}
+		b = b[n:]
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return
+			}
+			f(num, v, nil)
+			b = b[n:]
+		case protowire.BytesType:
+			raw, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return
+			}
+			f(num, 0, raw)
+			b = b[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return
+			}
+			b = b[n:]
+		}
+	}
+}
+
+// goalTracker keeps the latest state of the goals of a live keyed by goal ID.
+type goalTracker struct {
+	mu    sync.Mutex
+	goals map[int64]Goal
+}
+
+func newGoalTracker() *goalTracker {
+	return &goalTracker{
+		goals: make(map[int64]Goal),
+	}
+}
+
+func (g *goalTracker) process(e Event) []Event {
+	update, ok := e.(GoalUpdateEvent)
+	if !ok || update.Goal.ID == 0 {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.goals[update.Goal.ID] = update.Goal
+	return nil
+}
+
+func (g *goalTracker) all() []Goal {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	goals := make([]Goal, 0, len(g.goals))
+	for _, goal := range g.goals {
+		goals = append(goals, goal)
+	}
+	sort.Slice(goals, func(i, j int) bool {
+		return goals[i].StartTime < goals[j].StartTime
+	})
+	return goals
+}
+
+// Goals returns the latest state of all goals seen on the live so far, oldest first.
+func (l *Live) Goals() []Goal {
+	return l.goals.all()
+}
@@ -0,0 +1,57 @@
+package gotiktoklive
+
+import (
+	"testing"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoals(t *testing.T) {
+	live := newTestLive(t, nil)
+	assert.Empty(t, live.Goals())
+
+	live.emit(parseTestMsg(t, &pb.WebcastGoalUpdateMessage{
+		Common: &pb.Common{MsgId: 7300000000000000101},
+		Goal:   goalWithSubGoal(40, 100),
+	}))
+	goals := live.Goals()
+	require.Len(t, goals, 1)
+	progress, target := goals[0].Progress()
+	assert.Equal(t, 40, progress)
+	assert.Equal(t, 100, target)
+	assert.False(t, goals[0].Done())
+
+	// Later updates of the goal replace its state, going over the target counts as reaching it.
+	live.emit(parseTestMsg(t, &pb.WebcastGoalUpdateMessage{
+		Common: &pb.Common{MsgId: 7300000000000000102},
+		Goal:   goalWithSubGoal(150, 100),
+	}))
+	goals = live.Goals()
+	require.Len(t, goals, 1)
+	progress, _ = goals[0].Progress()
+	assert.Equal(t, 100, progress)
+	assert.Equal(t, 150, goals[0].TotalCoins)
+	assert.True(t, goals[0].Done())
+
+	// Goals are kept per ID, oldest first.
+	older := goalWithSubGoal(0, 10)
+	older.Id = 6
+	older.StartTime = 500
+	live.emit(parseTestMsg(t, &pb.WebcastGoalUpdateMessage{
+		Common: &pb.Common{MsgId: 7300000000000000103},
+		Goal:   older,
+	}))
+	// Updates without a goal, such as unpinning it, do not change the goals.
+	live.emit(parseTestMsg(t, &pb.WebcastGoalUpdateMessage{
+		Common: &pb.Common{MsgId: 7300000000000000104},
+		Unpin:  true,
+	}))
+	goals = live.Goals()
+	require.Len(t, goals, 2)
+	assert.Equal(t, []int64{6, 5}, []int64{goals[0].ID, goals[1].ID})
+	assert.False(t, goals[0].Done())
+
+	assert.False(t, Goal{}.Done(), "a goal without sub goals is never done")
+}
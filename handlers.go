@@ -0,0 +1,378 @@
+package gotiktoklive
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	defaultHandlerQueueSize = 100
+)
+
+type handlerMode int
+
+const (
+	handlerOrdered handlerMode = iota
+	handlerInline
+	handlerConcurrent
+)
+
+type handler struct {
+	match     func(Event) bool
+	call      func(Event)
+	mode      handlerMode
+	queueSize int
+	queue     chan Event
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// HandlerOption changes how a handler registered on a Live is called.
+type HandlerOption func(h *handler)
+
+// HandlerInline calls the handler directly on the goroutine reading the websocket. Events are delivered in order, but
+// a slow handler delays every other handler and the Events channel.
+func HandlerInline() HandlerOption {
+	return func(h *handler) {
+		h.mode = handlerInline
+	}
+}
+
+// HandlerConcurrent calls the handler on a new goroutine for every event. Events are not delivered in order.
+func HandlerConcurrent() HandlerOption {
+	return func(h *handler) {
+		h.mode = handlerConcurrent
+	}
+}
+
+// HandlerQueueSize sets how many events are queued for a handler running on its own goroutine, the default mode.
+// When the queue is full, reading the websocket waits for the handler to catch up. Defaults to 100.
+func HandlerQueueSize(size int) HandlerOption {
+	return func(h *handler) {
+		if size > 0 {
+			h.queueSize = size
+		}
+	}
+}
+
+// Handle registers f to be called for every event of type T. By default every handler gets its own goroutine and
+// receives its events in order. A handler that panics is recovered and the panic is passed to the error handler.
+// Events emitted before the handler was registered are not replayed.
+//
+// The returned function removes the handler. Events already queued for it are still delivered. Live.Close waits for
+// queued events to be handled, so a handler on its own goroutine must not call Close directly.
+func Handle[T Event](l *Live, f func(T), opts ...HandlerOption) (remove func()) {
+	return l.addHandler(&handler{
+		match: func(e Event) bool {
+			_, ok := e.(T)
+			return ok
+		},
+		call: func(e Event) {
+			f(e.(T))
+		},
+	}, opts)
+}
+
+// OnAny registers f to be called for every event. See Handle.
+func (l *Live) OnAny(f func(Event), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnChat registers f to be called for every ChatEvent. See Handle.
+func (l *Live) OnChat(f func(ChatEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnGift registers f to be called for every GiftEvent. See Handle.
+func (l *Live) OnGift(f func(GiftEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnGiftStreakUpdate registers f to be called for every GiftStreakUpdateEvent, see EnableGiftStreaks. See Handle.
+func (l *Live) OnGiftStreakUpdate(f func(GiftStreakUpdateEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnGiftStreakEnd registers f to be called for every GiftStreakEndEvent, see EnableGiftStreaks. See Handle.
+func (l *Live) OnGiftStreakEnd(f func(GiftStreakEndEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnLike registers f to be called for every LikeEvent. See Handle.
+func (l *Live) OnLike(f func(LikeEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnViewers registers f to be called for every ViewersEvent. See Handle.
+func (l *Live) OnViewers(f func(ViewersEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnControl registers f to be called for every ControlEvent. See Handle.
+func (l *Live) OnControl(f func(ControlEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnUser registers f to be called for every UserEvent, such as joins, follows and shares. See Handle.
+func (l *Live) OnUser(f func(UserEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnQuestion registers f to be called for every QuestionEvent. See Handle.
+func (l *Live) OnQuestion(f func(QuestionEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnRoom registers f to be called for every RoomEvent. See Handle.
+func (l *Live) OnRoom(f func(RoomEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnBattles registers f to be called for every BattlesEvent. See Handle.
+func (l *Live) OnBattles(f func(BattlesEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnMicBattle registers f to be called for every MicBattleEvent. See Handle.
+func (l *Live) OnMicBattle(f func(MicBattleEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnIntro registers f to be called for every IntroEvent. See Handle.
+func (l *Live) OnIntro(f func(IntroEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnRoomBanner registers f to be called for every RoomBannerEvent. See Handle.
+func (l *Live) OnRoomBanner(f func(RoomBannerEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnEnvelope registers f to be called for every EnvelopeEvent. See Handle.
+func (l *Live) OnEnvelope(f func(EnvelopeEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnCaption registers f to be called for every CaptionEvent. See Handle.
+func (l *Live) OnCaption(f func(CaptionEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnEmoteChat registers f to be called for every EmoteChatEvent. See Handle.
+func (l *Live) OnEmoteChat(f func(EmoteChatEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnBarrage registers f to be called for every BarrageEvent. See Handle.
+func (l *Live) OnBarrage(f func(BarrageEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnRankUpdate registers f to be called for every RankUpdateEvent. See Handle.
+func (l *Live) OnRankUpdate(f func(RankUpdateEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnRankText registers f to be called for every RankTextEvent. See Handle.
+func (l *Live) OnRankText(f func(RankTextEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnHourlyRank registers f to be called for every HourlyRankEvent. See Handle.
+func (l *Live) OnHourlyRank(f func(HourlyRankEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnBattleStarted registers f to be called for every BattleStartedEvent. See Handle.
+func (l *Live) OnBattleStarted(f func(BattleStartedEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnBattleScore registers f to be called for every BattleScoreEvent. See Handle.
+func (l *Live) OnBattleScore(f func(BattleScoreEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnBattleEnded registers f to be called for every BattleEndedEvent. See Handle.
+func (l *Live) OnBattleEnded(f func(BattleEndedEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnBattlePunishFinish registers f to be called for every BattlePunishFinishEvent. See Handle.
+func (l *Live) OnBattlePunishFinish(f func(BattlePunishFinishEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnBattleTask registers f to be called for every BattleTaskEvent. See Handle.
+func (l *Live) OnBattleTask(f func(BattleTaskEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnLinkMicMethod registers f to be called for every LinkMicMethodEvent. See Handle.
+func (l *Live) OnLinkMicMethod(f func(LinkMicMethodEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnLinkMicFanTicket registers f to be called for every LinkMicFanTicketEvent. See Handle.
+func (l *Live) OnLinkMicFanTicket(f func(LinkMicFanTicketEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnShopping registers f to be called for every ShoppingEvent. See Handle.
+func (l *Live) OnShopping(f func(ShoppingEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnMessageDeleted registers f to be called for every MessageDeletedEvent. See Handle.
+func (l *Live) OnMessageDeleted(f func(MessageDeletedEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnGoalUpdate registers f to be called for every GoalUpdateEvent. See Handle.
+func (l *Live) OnGoalUpdate(f func(GoalUpdateEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnSubscribe registers f to be called for every SubscribeEvent. See Handle.
+func (l *Live) OnSubscribe(f func(SubscribeEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnPollStart registers f to be called for every PollStartEvent. See Handle.
+func (l *Live) OnPollStart(f func(PollStartEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnPollUpdate registers f to be called for every PollUpdateEvent. See Handle.
+func (l *Live) OnPollUpdate(f func(PollUpdateEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnPollEnd registers f to be called for every PollEndEvent. See Handle.
+func (l *Live) OnPollEnd(f func(PollEndEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnReconnecting registers f to be called for every ReconnectingEvent. See Handle.
+func (l *Live) OnReconnecting(f func(ReconnectingEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnReconnected registers f to be called for every ReconnectedEvent. See Handle.
+func (l *Live) OnReconnected(f func(ReconnectedEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+// OnDisconnect registers f to be called once the live has disconnected for good. See Handle.
+func (l *Live) OnDisconnect(f func(*DisconnectEvent), opts ...HandlerOption) func() {
+	return Handle(l, f, opts...)
+}
+
+func (l *Live) addHandler(h *handler, opts []HandlerOption) func() {
+	h.queueSize = defaultHandlerQueueSize
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.stop = make(chan struct{})
+
+	l.handlersMu.Lock()
+	defer l.handlersMu.Unlock()
+	if l.handlersStopped {
+		return func() {}
+	}
+	if h.mode == handlerOrdered {
+		h.queue = make(chan Event, h.queueSize)
+		l.wg.Add(1)
+		go func() {
+			defer l.wg.Done()
+			h.run(l)
+		}()
+	}
+	// Copy on write so dispatch can iterate without holding the lock.
+	handlers := make([]*handler, len(l.handlers), len(l.handlers)+1)
+	copy(handlers, l.handlers)
+	l.handlers = append(handlers, h)
+
+	return func() {
+		l.handlersMu.Lock()
+		handlers := make([]*handler, 0, len(l.handlers))
+		for _, other := range l.handlers {
+			if other != h {
+				handlers = append(handlers, other)
+			}
+		}
+		l.handlers = handlers
+		l.handlersMu.Unlock()
+		h.close()
+	}
+}
+
+// dispatch passes an event to all registered handlers that accept it.
+func (l *Live) dispatch(e Event) {
+	l.handlersMu.RLock()
+	handlers := l.handlers
+	l.handlersMu.RUnlock()
+
+	for _, h := range handlers {
+		if !h.match(e) {
+			continue
+		}
+		switch h.mode {
+		case handlerInline:
+			h.invoke(l, e)
+		case handlerConcurrent:
+			go h.invoke(l, e)
+		default:
+			select {
+			case h.queue <- e:
+			case <-h.stop:
+			}
+		}
+	}
+}
+
+// stopHandlers stops all handlers once the live has ended. Queued events are still delivered.
+func (l *Live) stopHandlers() {
+	l.handlersMu.Lock()
+	handlers := l.handlers
+	l.handlers = nil
+	l.handlersStopped = true
+	l.handlersMu.Unlock()
+
+	for _, h := range handlers {
+		h.close()
+	}
+}
+
+func (h *handler) run(l *Live) {
+	for {
+		select {
+		case e := <-h.queue:
+			h.invoke(l, e)
+		case <-h.stop:
+			for {
+				select {
+				case e := <-h.queue:
+					h.invoke(l, e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (h *handler) invoke(l *Live, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			l.t.errHandler(fmt.Errorf("event handler for %T panicked: %v", e, r))
+		}
+	}()
+	h.call(e)
+}
+
+func (h *handler) close() {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+	})
+}
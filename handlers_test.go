@@ -0,0 +1,60 @@
+package gotiktoklive
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlersOrdered(t *testing.T) {
+	live := newTestLive(t, nil)
+
+	var got []string
+	done := make(chan struct{})
+	live.OnChat(func(e ChatEvent) {
+		got = append(got, e.Comment)
+		if len(got) == 3 {
+			close(done)
+		}
+	})
+	live.OnGift(func(e GiftEvent) {
+		t.Errorf("gift handler called for %+v", e)
+	})
+
+	for i := 0; i < 3; i++ {
+		live.emit(ChatEvent{Comment: fmt.Sprint(i)})
+	}
+	live.emit(ViewersEvent{Viewers: 10})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler not called")
+	}
+	assert.Equal(t, []string{"0", "1", "2"}, got)
+	assert.Len(t, live.Events, 4)
+}
+
+func TestHandlersPanicAndRemove(t *testing.T) {
+	live := newTestLive(t, nil)
+
+	var errs []interface{}
+	live.t.errHandler = func(i ...interface{}) {
+		errs = append(errs, i...)
+	}
+
+	calls := 0
+	remove := live.OnAny(func(e Event) {
+		calls++
+		panic("boom")
+	}, HandlerInline())
+
+	live.emit(LikeEvent{Likes: 1})
+	remove()
+	live.emit(LikeEvent{Likes: 2})
+
+	assert.Equal(t, 1, calls)
+	assert.Len(t, errs, 1)
+}
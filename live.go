@@ -14,10 +14,12 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
 
 	pb "github.com/steampoweredtaco/gotiktoklive/proto"
 
@@ -35,20 +37,47 @@ const (
 type Live struct {
 	t *TikTok
 
-	cursor   string
-	wss      net.Conn
-	wsURL    string
-	wsParams map[string]string
-	close    func()
-	done     func() <-chan struct{}
-	cancel   context.CancelFunc
+	cursor      string
+	internalExt string
+	wss         net.Conn
+	wssMu       sync.Mutex
+	wsURL       string
+	wsParams    map[string]string
+	close       func()
+	closed      chan struct{}
+	ctx         context.Context
+	done        func() <-chan struct{}
+	cancel      context.CancelFunc
+	replay      bool
 
 	ID       string
 	Info     *RoomInfo
 	GiftInfo *GiftInfo
 	Events   chan Event
 	chanSize int
+	emitMu   sync.Mutex
 	wg       *sync.WaitGroup
+
+	eventsClosed bool
+	processors   []func(Event) []Event
+	giftStreaks  *giftStreakTracker
+	stats        *statsTracker
+	polls        *pollTracker
+	goals        *goalTracker
+	battles      *battleTracker
+	gifts        *giftCatalog
+	giftsFailed  time.Time
+	giftsMu      sync.Mutex
+	chats        *chatBuffer
+
+	spill                   *eventSpill
+	dropped                 atomic.Uint64
+	droppedSinceReport      int
+	droppedGiftsSinceReport int
+
+	handlers        []*handler
+	handlersMu      sync.RWMutex
+	handlersStopped bool
 }
 
 func (t *TikTok) newLive(roomId string) *Live {
@@ -56,28 +85,57 @@ func (t *TikTok) newLive(roomId string) *Live {
 		t:        t,
 		ID:       roomId,
 		wg:       &sync.WaitGroup{},
-		Events:   make(chan Event, DEFAULT_EVENTS_CHAN_SIZE),
-		chanSize: DEFAULT_EVENTS_CHAN_SIZE,
+		Events:   make(chan Event, t.eventsChanSize),
+		chanSize: t.eventsChanSize,
+		closed:   make(chan struct{}),
 	}
 	t.mu.Lock()
 	t.streams += 1
+	t.lives[&live] = struct{}{}
 	t.mu.Unlock()
 
 	ctx, cancel := context.WithCancel(context.Background())
+	live.ctx = ctx
 	live.cancel = cancel
 	live.done = ctx.Done
+	live.stats = newStatsTracker(roomId, t.statsExcludeHistory, t.giftStreakTimeout > 0)
+	live.polls = newPollTracker()
+	live.goals = newGoalTracker()
+	live.battles = newBattleTracker()
+	live.processors = append(live.processors, live.stats.process, live.polls.process, live.goals.process, live.battles.process)
+	if t.chatBufferSize > 0 {
+		live.chats = newChatBuffer(t.chatBufferSize)
+		live.processors = append(live.processors, live.chats.process)
+	}
+	if t.giftStreakTimeout > 0 {
+		live.giftStreaks = newGiftStreakTracker(t.giftStreakTimeout)
+		live.processors = append(live.processors, live.giftStreaks.process)
+		live.wg.Add(1)
+		go func() {
+			defer live.wg.Done()
+			live.expireGiftStreaks()
+		}()
+	}
+	if t.backpressure == SpillToDisk {
+		if err := live.startSpill(); err != nil {
+			t.errHandler(fmt.Errorf("cannot spill events to disk, dropping the oldest events instead: %w", err))
+		}
+	}
 	o := sync.Once{}
 	live.close = func() {
 		o.Do(func() {
+			close(live.closed)
 			// cancel needs to be first as live.done is used to know to exit in all the
 			// various goroutines which should release the waitgroup. It is ok for anywhere
 			// to call cancel to trigger the other routines, but calls to close is only for
 			// cleanup and block till done
 			cancel()
-			live.wss.Close()
+			live.closeConn()
 			live.wg.Wait()
+			live.stopHandlers()
 			t.mu.Lock()
 			t.streams -= 1
+			delete(t.lives, &live)
 			t.mu.Unlock()
 		})
 	}
@@ -90,20 +148,51 @@ func (l *Live) Close() {
 	l.close()
 }
 
-func (l *Live) fetchRoom() error {
-	roomInfo, err := l.getRoomInfo()
+// emit passes an event to the registered handlers and sends it to the Events
+// channel, following the backpressure policy when the channel is full.
+// Processors see every event first and may derive new events from it, which are
+// emitted right after. Events missing gift details are completed from the gift
+// catalog first, see enrichEvent.
+func (l *Live) emit(e Event) {
+	e = l.enrichEvent(e)
+	l.emitMu.Lock()
+	defer l.emitMu.Unlock()
+	l.emitLocked(e)
+}
+
+func (l *Live) emitLocked(e Event) {
+	if l.eventsClosed {
+		return
+	}
+	var derived []Event
+	for _, process := range l.processors {
+		derived = append(derived, process(e)...)
+	}
+	l.dispatch(e)
+	l.send(e)
+	for _, d := range derived {
+		l.emitLocked(d)
+	}
+}
+
+// closeEvents closes the Events channel. Anything emitted afterwards is ignored.
+func (l *Live) closeEvents() {
+	l.emitMu.Lock()
+	defer l.emitMu.Unlock()
+	if !l.eventsClosed {
+		l.eventsClosed = true
+		close(l.Events)
+	}
+}
+
+func (l *Live) fetchRoom(ctx context.Context) error {
+	roomInfo, err := l.getRoomInfo(ctx)
 	if err != nil {
 		return err
 	}
 	l.Info = roomInfo
-	//
-	// giftInfo, err := l.getGiftInfo()
-	// if err != nil {
-	//	return err
-	// }
-	// l.GiftInfo = giftInfo
-
-	err = l.getRoomData()
+
+	err = l.getRoomData(ctx)
 	if err != nil {
 		return err
 	}
@@ -114,7 +203,12 @@ func (l *Live) fetchRoom() error {
 //
 //	but not start tracking a live stream.
 func (t *TikTok) GetRoomInfo(username string) (*RoomInfo, error) {
-	id, err := t.getRoomID(username)
+	return t.GetRoomInfoContext(context.Background(), username)
+}
+
+// GetRoomInfoContext is like GetRoomInfo but the requests are bound to ctx.
+func (t *TikTok) GetRoomInfoContext(ctx context.Context, username string) (*RoomInfo, error) {
+	id, err := t.getRoomID(ctx, username)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to fetch room ID by username")
 	}
@@ -124,7 +218,7 @@ func (t *TikTok) GetRoomInfo(username string) (*RoomInfo, error) {
 		ID: id,
 	}
 
-	roomInfo, err := l.getRoomInfo()
+	roomInfo, err := l.getRoomInfo(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to fetch room info")
 	}
@@ -138,37 +232,56 @@ func (t *TikTok) GetRoomInfo(username string) (*RoomInfo, error) {
 //
 // It will start a go routine and connect to the tiktok websocket.
 func (t *TikTok) TrackUser(username string) (*Live, error) {
-	id, err := t.getRoomID(username)
+	return t.TrackUserContext(context.Background(), username)
+}
+
+// TrackUserContext is like TrackUser but looking up the user and connecting to the room is bound to ctx. Once
+// connected, the ctx no longer affects the returned Live, use Live.Close to stop tracking.
+func (t *TikTok) TrackUserContext(ctx context.Context, username string) (*Live, error) {
+	id, err := t.getRoomID(ctx, username)
 	if err != nil {
 		return nil, err
 	}
 
-	return t.TrackRoom(id)
+	return t.TrackRoomContext(ctx, id)
 }
 
 // TrackRoom will start to track a room by room ID.
 // It will start a go routine and connect to the tiktok websocket.
 func (t *TikTok) TrackRoom(roomId string) (*Live, error) {
+	return t.TrackRoomContext(context.Background(), roomId)
+}
+
+// TrackRoomContext is like TrackRoom but connecting to the room is bound to ctx. Once connected, the ctx no longer
+// affects the returned Live, use Live.Close to stop tracking.
+func (t *TikTok) TrackRoomContext(ctx context.Context, roomId string) (*Live, error) {
+	select {
+	case <-t.done():
+		return nil, ErrTikTokClosed
+	default:
+	}
 	live := t.newLive(roomId)
 
-	if err := live.fetchRoom(); err != nil {
-		close(live.Events)
+	if err := live.fetchRoom(ctx); err != nil {
+		live.closeEvents()
+		live.close()
 		return nil, err
 	}
 
-	if err := live.connectRoom(); err != nil {
+	if err := live.connectRoom(ctx); err != nil {
+		live.close()
 		return nil, err
 	}
 
 	return live, nil
 }
 
-func (live *Live) connectRoom() error {
-	return live.tryConnectionUpgrade()
+func (live *Live) connectRoom(ctx context.Context) error {
+	return live.tryConnectionUpgrade(ctx)
 }
 
-func (t *TikTok) getRoomID(user string) (string, error) {
-	userInfo, err := t.GetUserInfo(user)
+func (t *TikTok) getRoomID(ctx context.Context, user string) (string, error) {
+	userInfo, err := t.GetUserInfoContext(ctx, user)
 	if err != nil {
 		return "", err
 	}
@@ -179,13 +292,13 @@ func (t *TikTok) getRoomID(user string) (string, error) {
 	return userInfo.RoomID, nil
 }
 
-func (l *Live) getRoomInfo() (*RoomInfo, error) {
+func (l *Live) getRoomInfo(ctx context.Context) (*RoomInfo, error) {
 	t := l.t
 
 	params := copyMap(defaultGETParams)
 	params["room_id"] = l.ID
 
-	body, _, err := t.sendRequest(&reqOptions{
+	body, _, err := t.sendRequest(ctx, &reqOptions{
 		Endpoint: urlRoomInfo,
 		Query:    params,
 	}, nil)
@@ -204,28 +317,14 @@ func (l *Live) getRoomInfo() (*RoomInfo, error) {
 	return rsp.RoomInfo, nil
 }
 
-func (l *Live) getGiftInfo() (*GiftInfo, error) {
-	t := l.t
-
-	params := copyMap(defaultGETParams)
-	params["room_id"] = l.ID
-
-	body, _, err := t.sendRequest(&reqOptions{
-		Endpoint: urlGiftInfo,
-		Query:    params,
-	}, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var rsp giftInfoRsp
-	if err := json.Unmarshal(body, &rsp); err != nil {
-		return nil, err
-	}
-	return rsp.GiftInfo, nil
+func (l *Live) getRoomData(ctx context.Context) error {
+	return l.fetchRoomData(ctx, false)
 }
 
-func (l *Live) getRoomData() error {
+// fetchRoomData fetches the room data from the saved cursor and internal_ext and emits the messages it contains. When
+// resuming after a reconnect, TikTok sends recent messages again as history, these were already emitted and are
+// dropped.
+func (l *Live) fetchRoomData(ctx context.Context, resume bool) error {
 	t := l.t
 
 	params := copyMap(defaultGETParams)
@@ -234,8 +333,11 @@ func (l *Live) getRoomData() error {
 	if l.cursor != "" {
 		params["cursor"] = l.cursor
 	}
+	if l.internalExt != "" {
+		params["internal_ext"] = l.internalExt
+	}
 
-	body, headers, err := t.sendRequest(&reqOptions{
+	body, headers, err := t.sendRequest(ctx, &reqOptions{
 		Endpoint: urlRoomData,
 		Query:    params,
 	}, nil)
@@ -263,6 +365,7 @@ func (l *Live) getRoomData() error {
 	}
 
 	l.cursor = rsp.Cursor
+	l.internalExt = string(rsp.InternalExt)
 	if rsp.PushServer != "" && rsp.RouteParamsMap != nil {
 		l.wsURL = rsp.PushServer
 		l.wsParams = make(map[string]string)
@@ -282,7 +385,10 @@ func (l *Live) getRoomData() error {
 			// but can cause problems if we send the events upstream
 			continue
 		}
-		l.Events <- parsed
+		if resume && parsed.IsHistory() {
+			continue
+		}
+		l.emit(parsed)
 	}
 
 	return nil
@@ -296,6 +402,11 @@ func (l *Live) getRoomData() error {
 //
 // The stream start time can be found in Live.Info.CreateTime as epoch seconds.
 func (l *Live) DownloadStream(file ...string) error {
+	return l.DownloadStreamContext(context.Background(), file...)
+}
+
+// DownloadStreamContext is like DownloadStream but the download is stopped when ctx is done.
+func (l *Live) DownloadStreamContext(ctx context.Context, file ...string) error {
 	// Check if ffmpeg is installed
 	if _, err := exec.LookPath("ffmpeg"); err != nil {
 		return ErrFFMPEGNotFound
@@ -331,7 +442,7 @@ func (l *Live) DownloadStream(file ...string) error {
 	// important to come after the -i
 	options = append(options, "-c:v", "libx264", "-c:a", "aac", "-bufsize", "2M", "-fflags", "+discardcorrupt",
 		"-fflags", "+genpts", path)
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	cmd := exec.CommandContext(ctx, "ffmpeg", options...)
 
 	stdin, err := cmd.StdinPipe()
@@ -362,6 +473,8 @@ func (l *Live) DownloadStream(file ...string) error {
 			select {
 			case <-l.done():
 				break drainFor
+			case <-ctx.Done():
+				break drainFor
 			// Needs to be drained or deadlocks can occur, maybe at some point make a download option
 			// where the library user needs to drain
 			case _, ok := <-l.Events:
@@ -425,8 +538,8 @@ func (l *Live) DownloadStream(file ...string) error {
 		finished = true
 		if err != nil {
 			l.t.errHandler(fmt.Sprintf("Download for failed: %s", err))
+			return
 		}
-		return
 		l.t.infoHandler(fmt.Sprintf("Download for %s finished!", l.Info.Owner.Username))
 	}(cmd, stdout, stderr)
 	l.wg.Wait()
@@ -435,7 +548,7 @@ func (l *Live) DownloadStream(file ...string) error {
 	return nil
 }
 
-func (t *TikTok) signURL(reqUrl string, options *reqOptions) ([]byte, http.Header, error) {
+func (t *TikTok) signURL(ctx context.Context, reqUrl string, options *reqOptions) ([]byte, http.Header, error) {
 	query := map[string]string{
 		"client":  t.clientName,
 		"uuc":     strconv.Itoa(t.streams),
@@ -448,8 +561,10 @@ func (t *TikTok) signURL(reqUrl string, options *reqOptions) ([]byte, http.Heade
 	// A badly formed implementation using this library might spam connection requests (ask me
 	// how I know) this limiter is a safety guard to never go over the signer's advertised
 	// capabilities so the client does not exceed limits or get banned from the signer.
-	t.limiter.Take()
-	body, header, err := t.sendRequest(&reqOptions{
+	if err := t.takeLimiter(ctx); err != nil {
+		return nil, nil, fmt.Errorf("waiting for signing limiter: %w", err)
+	}
+	body, header, err := t.sendRequest(ctx, &reqOptions{
 		URI:      t.signerUrl,
 		Endpoint: urlSignReq,
 		Query:    query,
@@ -461,6 +576,17 @@ func (t *TikTok) signURL(reqUrl string, options *reqOptions) ([]byte, http.Heade
 	return body, header, nil
 }
 
+// takeLimiter blocks until the signing limiter allows another request or ctx is done. A canceled wait gives its slot
+// back to the limiter.
+func (t *TikTok) takeLimiter(ctx context.Context) error {
+	return t.limiter.Wait(ctx)
+}
+
+// newSignLimiter returns a limiter spacing sign requests evenly to at most perMinute a minute, without bursts.
+func newSignLimiter(perMinute int) *rate.Limiter {
+	return rate.NewLimiter(rate.Every(time.Minute/time.Duration(max(perMinute, 1))), 1)
+}
+
 // Only able to get this while logged in
 // func (l *Live) GetRankList() (*RankList, error) {
 // 	t := l.t
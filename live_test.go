@@ -0,0 +1,80 @@
+package gotiktoklive
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestTikTok creates a TikTok instance that does not talk to TikTok or a signer.
+func newTestTikTok(t *testing.T) *TikTok {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	logf := func(i ...interface{}) {
+		t.Log(i...)
+	}
+	return &TikTok{
+		wg:           &sync.WaitGroup{},
+		done:         ctx.Done,
+		cancel:       cancel,
+		lives:        make(map[*Live]struct{}),
+		mu:           &sync.Mutex{},
+		infoHandler:  logf,
+		warnHandler:  logf,
+		debugHandler: logf,
+		errHandler:   logf,
+
+		eventsChanSize: DEFAULT_EVENTS_CHAN_SIZE,
+		spillDir:       t.TempDir(),
+	}
+}
+
+// newTestLive creates a Live that is not connected to anything so events can be emitted by hand.
+func newTestLive(t *testing.T, tiktok *TikTok) *Live {
+	if tiktok == nil {
+		tiktok = newTestTikTok(t)
+	}
+	live := tiktok.newLive("1")
+	t.Cleanup(func() {
+		// Nothing reads the websocket to stop the handlers when it ends.
+		live.stopHandlers()
+		live.Close()
+	})
+	return live
+}
+
+// parseTestMsg encodes a webcast message like TikTok does and parses it into an event.
+func parseTestMsg(t *testing.T, m proto.Message) Event {
+	payload, err := proto.Marshal(m)
+	require.NoError(t, err)
+	logf := func(i ...interface{}) {
+		t.Log(i...)
+	}
+	e, err := parseMsg(&pb.WebcastResponse_Message{
+		Method:  string(proto.MessageName(m)),
+		Payload: payload,
+	}, logf, logf, false)
+	require.NoError(t, err)
+	return e
+}
+
+func TestTakeLimiterCanceled(t *testing.T) {
+	tiktok := newTestTikTok(t)
+	tiktok.limiter = newSignLimiter(60)
+	require.NoError(t, tiktok.takeLimiter(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, tiktok.takeLimiter(ctx), context.Canceled)
+
+	// The canceled wait did not use up the next slot.
+	r := tiktok.limiter.Reserve()
+	defer r.Cancel()
+	assert.LessOrEqual(t, r.Delay(), time.Second)
+}
@@ -1,5 +1,11 @@
 package gotiktoklive
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type TikTokLiveOption func(t *TikTok) error
 
 // SigningApiKey sets the singer API key.
@@ -19,6 +25,30 @@ func SigningUrl(url string) TikTokLiveOption {
 	}
 }
 
+// BaseUrl sets the URL of the TikTok website used to look up users. The default is https://www.tiktok.com/. Mostly
+// useful to point the library at a fake server in tests, see the tiktoktest package.
+func BaseUrl(url string) TikTokLiveOption {
+	return func(t *TikTok) error {
+		if !strings.HasSuffix(url, "/") {
+			url += "/"
+		}
+		t.baseUrl = url
+		return nil
+	}
+}
+
+// ApiUrl sets the URL of the TikTok webcast API. The default is https://webcast.tiktok.com/webcast/. Mostly useful to
+// point the library at a fake server in tests, see the tiktoktest package.
+func ApiUrl(url string) TikTokLiveOption {
+	return func(t *TikTok) error {
+		if !strings.HasSuffix(url, "/") {
+			url += "/"
+		}
+		t.apiUrl = url
+		return nil
+	}
+}
+
 // DisableSigningLimitsValidation will disable querying the signer for limits and using those as the reasonable limits
 // for signing requests per second. Instead, this library will be limited to signing only 5 signing requests per minute
 // and may limit functionality compared to the request limit the signer provides.
@@ -41,6 +71,132 @@ func EnableExtraWebCastDebug(t *TikTok) error {
 	return nil
 }
 
+// DisableReconnect stops a Live from reconnecting when the websocket drops. Instead, a DisconnectEvent is emitted and
+// the Events channel is closed on the first connection error.
+func DisableReconnect(t *TikTok) error {
+	t.shouldReconnect = false
+	return nil
+}
+
+// ReconnectBackoff sets the delay before the first reconnect attempt and the maximum delay between attempts. The delay
+// doubles after every failed attempt. Defaults to 1 second and 30 seconds.
+func ReconnectBackoff(min, max time.Duration) TikTokLiveOption {
+	return func(t *TikTok) error {
+		if min <= 0 || max < min {
+			return fmt.Errorf("invalid reconnect backoff, min %s max %s", min, max)
+		}
+		t.reconnectMinBackoff = min
+		t.reconnectMaxBackoff = max
+		return nil
+	}
+}
+
+// ReconnectMaxAttempts sets how many times in a row a Live tries to reconnect before giving up and emitting a
+// DisconnectEvent. Zero means to retry until the Live is closed. Defaults to 10.
+func ReconnectMaxAttempts(attempts int) TikTokLiveOption {
+	return func(t *TikTok) error {
+		if attempts < 0 {
+			return fmt.Errorf("invalid reconnect max attempts %d", attempts)
+		}
+		t.reconnectMaxAttempts = attempts
+		return nil
+	}
+}
+
+// EventsChanSize sets the buffer size of Live.Events. Defaults to 100.
+func EventsChanSize(size int) TikTokLiveOption {
+	return func(t *TikTok) error {
+		if size <= 0 {
+			return fmt.Errorf("invalid events channel size %d", size)
+		}
+		t.eventsChanSize = size
+		return nil
+	}
+}
+
+// EventsBackpressure sets what happens to new events when Live.Events is full. Whenever events had to be dropped, a
+// DroppedEventsEvent is sent once there is room again. Defaults to DropOldest.
+func EventsBackpressure(policy BackpressurePolicy) TikTokLiveOption {
+	return func(t *TikTok) error {
+		if policy < DropOldest || policy > SpillToDisk {
+			return fmt.Errorf("invalid backpressure policy %s", policy)
+		}
+		t.backpressure = policy
+		return nil
+	}
+}
+
+// EventsSpillDir sets the directory for the temporary files used by the SpillToDisk policy. Defaults to os.TempDir().
+func EventsSpillDir(dir string) TikTokLiveOption {
+	return func(t *TikTok) error {
+		t.spillDir = dir
+		return nil
+	}
+}
+
+// EnableGiftStreaks aggregates combo gifts into streaks. While a streak is running a GiftStreakUpdateEvent is emitted
+// for every new count, and a single GiftStreakEndEvent with the total count and diamonds once the streak ends or no
+// update was seen for timeout. GiftEvents are still emitted as before.
+func EnableGiftStreaks(timeout time.Duration) TikTokLiveOption {
+	return func(t *TikTok) error {
+		if timeout <= 0 {
+			return fmt.Errorf("invalid gift streak timeout %s", timeout)
+		}
+		t.giftStreakTimeout = timeout
+		return nil
+	}
+}
+
+// EnableChatBuffer keeps the last size chat messages of every live in memory. When moderators remove messages or
+// users, the matching messages are marked as deleted, see Live.RecentChats.
+func EnableChatBuffer(size int) TikTokLiveOption {
+	return func(t *TikTok) error {
+		if size <= 0 {
+			return fmt.Errorf("invalid chat buffer size %d", size)
+		}
+		t.chatBufferSize = size
+		return nil
+	}
+}
+
+// GiftCatalogTTL sets how long the gift catalog of a room is cached, see TikTok.GetGiftCatalog. 0 fetches it on every
+// call. Defaults to an hour.
+func GiftCatalogTTL(ttl time.Duration) TikTokLiveOption {
+	return func(t *TikTok) error {
+		if ttl < 0 {
+			return fmt.Errorf("invalid gift catalog ttl %s", ttl)
+		}
+		t.giftCatalogTTL = ttl
+		return nil
+	}
+}
+
+// StatsExcludeHistory leaves history events out of Live.Stats. TikTok sends recent messages again as history on every
+// (re)connect, which would otherwise be counted twice.
+func StatsExcludeHistory(t *TikTok) error {
+	t.statsExcludeHistory = true
+	return nil
+}
+
+// ReplaySpeed sets how fast ReplayTrace plays back a trace. 1 keeps the original timing, 2 plays it twice as fast and 0
+// replays all frames without waiting. Defaults to 1.
+func ReplaySpeed(speed float64) TikTokLiveOption {
+	return func(t *TikTok) error {
+		if speed < 0 {
+			return fmt.Errorf("invalid replay speed %v", speed)
+		}
+		t.replaySpeed = speed
+		return nil
+	}
+}
+
+// EnableInterruptHandler restores the old behaviour of trapping SIGINT and SIGTERM. On a signal the instance is closed
+// and the process exits with os.Exit(0). Without this option, call TikTok.Close when shutting down.
+func EnableInterruptHandler(t *TikTok) error {
+	t.enableInterruptHandler = true
+	return nil
+}
+
 // EnableWSTrace will put traces for all websocket messages into the given file. The file will be overwritten so
 // if you want multiple traces make sure handle giving a unique filename each startup.
 func EnableWSTrace(file string) TikTokLiveOption {
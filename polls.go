@@ -0,0 +1,158 @@
+package gotiktoklive
+
+import (
+	"sort"
+	"sync"
+)
+
+// Poll is the state of a poll of a live, see Live.Polls.
+type Poll struct {
+	ID        int64
+	Title     string
+	Options   []PollOption
+	StartTime int64
+	EndTime   int64
+	Operator  *User
+	Ended     bool
+	EndType   int
+}
+
+// Winners returns the options with the most votes, more than one on a tie. It returns nil if there are no votes.
+func (p Poll) Winners() []PollOption {
+	var winners []PollOption
+	best := 0
+	for _, o := range p.Options {
+		switch {
+		case o.Votes > best:
+			best = o.Votes
+			winners = []PollOption{o}
+		case o.Votes == best && best > 0:
+			winners = append(winners, o)
+		}
+	}
+	return winners
+}
+
+// TotalVotes returns the number of votes over all options.
+func (p Poll) TotalVotes() int {
+	total := 0
+	for _, o := range p.Options {
+		total += o.Votes
+	}
+	return total
+}
+
+// pollTracker keeps the state of the polls of a live keyed by poll ID.
+type pollTracker struct {
+	mu    sync.Mutex
+	polls map[int64]*Poll
+}
+
+func newPollTracker() *pollTracker {
+	return &pollTracker{
+		polls: make(map[int64]*Poll),
+	}
+}
+
+func (p *pollTracker) process(e Event) []Event {
+	switch e := e.(type) {
+	case PollStartEvent:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		poll := p.poll(e.PollID)
+		poll.Title = e.Title
+		poll.StartTime = e.StartTime
+		poll.EndTime = e.EndTime
+		poll.Operator = e.Operator
+		poll.Options = mergePollOptions(poll.Options, e.Options)
+	case PollUpdateEvent:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		poll := p.poll(e.PollID)
+		if !poll.Ended {
+			poll.Options = mergePollOptions(poll.Options, e.Options)
+		}
+	case PollEndEvent:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		poll := p.poll(e.PollID)
+		poll.Ended = true
+		poll.EndType = e.EndType
+		poll.Options = mergePollOptions(poll.Options, e.Options)
+	}
+	return nil
+}
+
+func (p *pollTracker) poll(id int64) *Poll {
+	poll, ok := p.polls[id]
+	if !ok {
+		poll = &Poll{ID: id}
+		p.polls[id] = poll
+	}
+	return poll
+}
+
+// mergePollOptions updates the vote counts of the known options by index. Updates do not always carry the text of
+// an option, so it is kept from earlier messages.
+func mergePollOptions(known, update []PollOption) []PollOption {
+	merged := make([]PollOption, len(known))
+	copy(merged, known)
+	for _, o := range update {
+		i := sort.Search(len(merged), func(i int) bool { return merged[i].Index >= o.Index })
+		if i < len(merged) && merged[i].Index == o.Index {
+			if o.Text == "" {
+				o.Text = merged[i].Text
+			}
+			if o.Voters == nil {
+				o.Voters = merged[i].Voters
+			}
+			merged[i] = o
+			continue
+		}
+		merged = append(merged, PollOption{})
+		copy(merged[i+1:], merged[i:])
+		merged[i] = o
+	}
+	return merged
+}
+
+func (p *pollTracker) get(id int64) (Poll, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	poll, ok := p.polls[id]
+	if !ok {
+		return Poll{}, false
+	}
+	return copyPoll(poll), true
+}
+
+func (p *pollTracker) all() []Poll {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	polls := make([]Poll, 0, len(p.polls))
+	for _, poll := range p.polls {
+		polls = append(polls, copyPoll(poll))
+	}
+	sort.Slice(polls, func(i, j int) bool {
+		return polls[i].StartTime < polls[j].StartTime
+	})
+	return polls
+}
+
+func copyPoll(p *Poll) Poll {
+	poll := *p
+	poll.Options = make([]PollOption, len(p.Options))
+	copy(poll.Options, p.Options)
+	return poll
+}
+
+// Poll returns the state of a poll of the live by its ID, as seen in the poll events. Once the poll has ended, Ended
+// is set and the options hold the final result.
+func (l *Live) Poll(id int64) (Poll, bool) {
+	return l.polls.get(id)
+}
+
+// Polls returns all polls seen on the live so far, oldest first.
+func (l *Live) Polls() []Poll {
+	return l.polls.all()
+}
@@ -0,0 +1,110 @@
+package gotiktoklive
+
+import (
+	"testing"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolls(t *testing.T) {
+	live := newTestLive(t, nil)
+
+	start := parseTestMsg(t, &pb.WebcastPollMessage{
+		Common: &pb.Common{MsgId: 7100000000000000001},
+		PollId: 42,
+		StartContent: &pb.PollStartContent{
+			Title: "Best fruit?",
+			OptionList: []*pb.PollOptionInfo{
+				{OptionIdx: 1, DisplayContent: "Banana"},
+				{OptionIdx: 0, DisplayContent: "Apple"},
+			},
+			Operator: &pb.User{Nickname: "host"},
+		},
+	})
+	require.IsType(t, PollStartEvent{}, start)
+	assert.Equal(t, "Best fruit?", start.(PollStartEvent).Title)
+	live.emit(start)
+
+	update := parseTestMsg(t, &pb.WebcastPollMessage{
+		Common: &pb.Common{MsgId: 7100000000000000002},
+		PollId: 42,
+		UpdateContent: &pb.PollUpdateVotesContent{
+			OptionList: []*pb.PollOptionInfo{
+				{OptionIdx: 0, Votes: 3, VoteUserList: []*pb.VoteUser{{UserId: 7, NickName: "voter"}}},
+				{OptionIdx: 1, Votes: 5},
+			},
+		},
+	})
+	require.IsType(t, PollUpdateEvent{}, update)
+	live.emit(update)
+
+	poll, ok := live.Poll(42)
+	require.True(t, ok)
+	assert.False(t, poll.Ended)
+	assert.Equal(t, []PollOption{
+		{Index: 0, Text: "Apple", Votes: 3, Voters: []*User{{ID: 7, Nickname: "voter", ExtraAttributes: &ExtraAttributes{}}}},
+		{Index: 1, Text: "Banana", Votes: 5},
+	}, poll.Options)
+
+	end := parseTestMsg(t, &pb.WebcastPollMessage{
+		Common: &pb.Common{MsgId: 7100000000000000003},
+		PollId: 42,
+		EndContent: &pb.PollEndContent{
+			EndType: 1,
+			OptionList: []*pb.PollOptionInfo{
+				{OptionIdx: 0, Votes: 6},
+				{OptionIdx: 1, Votes: 6},
+			},
+		},
+	})
+	require.IsType(t, PollEndEvent{}, end)
+	live.emit(end)
+
+	poll, ok = live.Poll(42)
+	require.True(t, ok)
+	assert.True(t, poll.Ended)
+	assert.Equal(t, "Best fruit?", poll.Title)
+	assert.Equal(t, 12, poll.TotalVotes())
+	assert.Len(t, poll.Winners(), 2)
+	assert.Len(t, live.Polls(), 1)
+
+	_, ok = live.Poll(1)
+	assert.False(t, ok)
+}
+
+func TestPollTransitions(t *testing.T) {
+	live := newTestLive(t, nil)
+
+	// Joining while a poll runs, the first message may be an update.
+	live.emit(PollUpdateEvent{PollID: 7, Options: []PollOption{{Index: 1, Votes: 2}}})
+	poll, ok := live.Poll(7)
+	require.True(t, ok)
+	assert.Equal(t, []PollOption{{Index: 1, Votes: 2}}, poll.Options)
+
+	// The start fills in the poll and the option texts, later updates keep them.
+	live.emit(PollStartEvent{PollID: 7, Title: "Yes or no?", StartTime: 100, Options: []PollOption{{Index: 0, Text: "Yes"}, {Index: 1, Text: "No"}}})
+	live.emit(PollUpdateEvent{PollID: 7, Options: []PollOption{{Index: 0, Votes: 4}}})
+	poll, _ = live.Poll(7)
+	assert.Equal(t, "Yes or no?", poll.Title)
+	assert.Equal(t, []PollOption{{Index: 0, Text: "Yes", Votes: 4}, {Index: 1, Text: "No"}}, poll.Options)
+	assert.Equal(t, []PollOption{{Index: 0, Text: "Yes", Votes: 4}}, poll.Winners())
+
+	live.emit(PollEndEvent{PollID: 7, EndType: 1, Options: []PollOption{{Index: 0, Votes: 5}, {Index: 1, Votes: 3}}})
+	// Updates arriving after the end do not change the result.
+	live.emit(PollUpdateEvent{PollID: 7, Options: []PollOption{{Index: 1, Votes: 9}}})
+	poll, _ = live.Poll(7)
+	assert.True(t, poll.Ended)
+	assert.Equal(t, 1, poll.EndType)
+	assert.Equal(t, 8, poll.TotalVotes())
+	assert.Equal(t, []PollOption{{Index: 0, Text: "Yes", Votes: 5}, {Index: 1, Text: "No", Votes: 3}}, poll.Options)
+
+	// A second poll is kept next to the first, oldest first.
+	live.emit(PollStartEvent{PollID: 8, Title: "Again?", StartTime: 200})
+	polls := live.Polls()
+	require.Len(t, polls, 2)
+	assert.Equal(t, []int64{7, 8}, []int64{polls[0].ID, polls[1].ID})
+	assert.False(t, polls[1].Ended)
+	assert.Nil(t, polls[1].Winners(), "a poll without votes has no winner")
+}
@@ -0,0 +1,77 @@
+package gotiktoklive_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steampoweredtaco/gotiktoklive"
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/steampoweredtaco/gotiktoklive/tiktoktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconnect(t *testing.T) {
+	srv := tiktoktest.NewServer()
+	defer srv.Close()
+	room := srv.AddRoom("tester", "7000000000000000005")
+	room.FetchMessages(&pb.WebcastChatMessage{Common: &pb.Common{MsgId: 8000000000000000031}, Content: "fetched"})
+
+	tiktok := newServerTikTok(t, srv,
+		gotiktoklive.ReconnectBackoff(10*time.Millisecond, 25*time.Millisecond),
+		gotiktoklive.ReconnectMaxAttempts(5))
+
+	live, err := tiktok.TrackUser("tester")
+	require.NoError(t, err)
+	assert.Equal(t, "fetched", nextEvent[gotiktoklive.ChatEvent](t, live).Comment)
+	require.NoError(t, room.Push(&pb.WebcastChatMessage{Common: &pb.Common{MsgId: 8000000000000000032}, Content: "pushed"}))
+	assert.Equal(t, "pushed", nextEvent[gotiktoklive.ChatEvent](t, live).Comment)
+
+	// The first two attempts fail, the delay doubles up to the maximum backoff.
+	room.FailFetches(2)
+	room.Disconnect()
+	events := eventsUntil[gotiktoklive.ReconnectedEvent](t, live)
+	var delays []time.Duration
+	for _, e := range events {
+		switch e := e.(type) {
+		case gotiktoklive.ReconnectingEvent:
+			assert.Equal(t, len(delays)+1, e.Attempt)
+			delays = append(delays, e.Delay)
+		case gotiktoklive.ChatEvent:
+			t.Errorf("fetched message emitted again on resume: %q", e.Comment)
+		}
+	}
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 25 * time.Millisecond}, delays)
+	assert.Equal(t, 3, events[len(events)-1].(gotiktoklive.ReconnectedEvent).Attempts)
+
+	// The live resumed from the cursor of the first fetch.
+	assert.Equal(t, []string{"", "cursor-1"}, room.FetchCursors())
+	require.NoError(t, room.Push(&pb.WebcastChatMessage{Common: &pb.Common{MsgId: 8000000000000000033}, Content: "resumed"}))
+	assert.Equal(t, "resumed", nextEvent[gotiktoklive.ChatEvent](t, live).Comment)
+}
+
+func TestReconnectMaxAttempts(t *testing.T) {
+	srv := tiktoktest.NewServer()
+	defer srv.Close()
+	room := srv.AddRoom("tester", "7000000000000000006")
+
+	tiktok := newServerTikTok(t, srv,
+		gotiktoklive.ReconnectBackoff(time.Millisecond, time.Millisecond),
+		gotiktoklive.ReconnectMaxAttempts(2))
+
+	live, err := tiktok.TrackUser("tester")
+	require.NoError(t, err)
+	<-room.Connected()
+
+	room.FailFetches(100)
+	room.Disconnect()
+	attempts := 0
+	for _, e := range eventsUntil[*gotiktoklive.DisconnectEvent](t, live) {
+		if _, ok := e.(gotiktoklive.ReconnectingEvent); ok {
+			attempts++
+		}
+	}
+	assert.Equal(t, 2, attempts)
+	_, ok := <-live.Events
+	assert.False(t, ok, "events are closed after giving up")
+}
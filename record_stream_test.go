@@ -0,0 +1,47 @@
+package gotiktoklive_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steampoweredtaco/gotiktoklive"
+	"github.com/steampoweredtaco/gotiktoklive/tiktoktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordStream(t *testing.T) {
+	srv := tiktoktest.NewServer()
+	defer srv.Close()
+	room := srv.AddRoom("tester", "7000000000000000003")
+	room.SetStream([]byte("FLV\x01segment0"), []byte("segment1"))
+
+	tiktok := newServerTikTok(t, srv)
+	live, err := tiktok.TrackUser("tester")
+	require.NoError(t, err)
+
+	for _, format := range []gotiktoklive.StreamFormat{gotiktoklive.StreamFLV, gotiktoklive.StreamHLS} {
+		t.Run(format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, live.RecordStreamTo(context.Background(), &buf, format))
+			assert.Equal(t, "FLV\x01segment0segment1", buf.String())
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "recording")
+	require.NoError(t, live.RecordStream(gotiktoklive.StreamHLS, path))
+	b, err := os.ReadFile(path + ".ts")
+	require.NoError(t, err)
+	assert.Equal(t, "FLV\x01segment0segment1", string(b))
+
+	// A segment that fails is fetched again, one that keeps failing is skipped.
+	room.SetStream([]byte("segment0"), []byte("segment1"), []byte("segment2"))
+	room.FailSegment(0, 2)
+	room.FailSegment(1, 10)
+	var buf bytes.Buffer
+	require.NoError(t, live.RecordStreamTo(context.Background(), &buf, gotiktoklive.StreamHLS))
+	assert.Equal(t, "segment0segment2", buf.String())
+}
@@ -0,0 +1,322 @@
+package gotiktoklive
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StreamFormat is the container a stream is recorded in, see Live.RecordStream.
+type StreamFormat int
+
+const (
+	// StreamFLV records the FLV stream, in the best quality available.
+	StreamFLV StreamFormat = iota
+	// StreamHLS records the MPEG-TS segments of the HLS playlist.
+	StreamHLS
+)
+
+func (f StreamFormat) String() string {
+	switch f {
+	case StreamFLV:
+		return "flv"
+	case StreamHLS:
+		return "hls"
+	}
+	return fmt.Sprintf("StreamFormat(%d)", int(f))
+}
+
+func (f StreamFormat) ext() string {
+	if f == StreamHLS {
+		return ".ts"
+	}
+	return ".flv"
+}
+
+const (
+	// hlsStaleTargets is the number of target durations the HLS playlist may go without new segments before the
+	// stream is considered over.
+	hlsStaleTargets = 6
+	// hlsSegmentAttempts is how many times a segment is fetched before it is skipped.
+	hlsSegmentAttempts = 3
+	// hlsSegmentRetry is the delay before fetching a segment again, multiplied by the attempt.
+	hlsSegmentRetry = 200 * time.Millisecond
+)
+
+// RecordStream records the stream to a file without ffmpeg. Unlike DownloadStream the stream is not transcoded, the
+// FLV stream or the TS segments are written as they are received. The file defaults to the username and start time
+// of the live, the extension of the format is added if missing. RecordStream blocks until the stream ends or the live
+// is closed.
+func (l *Live) RecordStream(format StreamFormat, file ...string) error {
+	return l.RecordStreamContext(context.Background(), format, file...)
+}
+
+// RecordStreamContext is like RecordStream but the recording is stopped when ctx is done.
+func (l *Live) RecordStreamContext(ctx context.Context, format StreamFormat, file ...string) error {
+	ext := format.ext()
+	var path string
+	if len(file) > 0 {
+		path = file[0]
+		if !strings.HasSuffix(path, ext) {
+			path += ext
+		}
+	} else {
+		path = fmt.Sprintf("%s-%s%s", l.Info.Owner.Username, time.Unix(l.Info.CreateTime, 0).Format("2006y01m02dT15h04m05s"), ext)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t := strings.TrimSuffix(path, ext)
+		path = fmt.Sprintf("%s-%d%s", t, time.Now().Unix(), ext)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	l.t.infoHandler(fmt.Sprintf("Started recording stream by %s to %s", l.Info.Owner.Username, path))
+	err = l.RecordStreamTo(ctx, f, format)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	l.t.infoHandler(fmt.Sprintf("Recording for %s finished!", l.Info.Owner.Username))
+	return nil
+}
+
+// RecordStreamTo is like RecordStreamContext but writes the stream to w.
+func (l *Live) RecordStreamTo(ctx context.Context, w io.Writer, format StreamFormat) error {
+	if l.Info == nil {
+		return ErrURLNotFound
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-l.done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var err error
+	switch format {
+	case StreamFLV:
+		err = l.recordFLV(ctx, w)
+	case StreamHLS:
+		err = l.recordHLS(ctx, w)
+	default:
+		return fmt.Errorf("unknown stream format %s", format)
+	}
+	// Stopping the recording is not an error.
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// streamClient returns a client for the stream CDN using the transport, and so the proxy, of the TikTok instance.
+// Unlike the API client it follows redirects.
+func (l *Live) streamClient() *http.Client {
+	return &http.Client{Transport: l.t.c.Transport, Jar: l.t.c.Jar}
+}
+
+func (l *Live) getStream(ctx context.Context, c *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Referer", referer)
+	req.Header.Set("Origin", origin)
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s for %s", resp.Status, url)
+	}
+	return resp, nil
+}
+
+func (l *Live) recordFLV(ctx context.Context, w io.Writer) error {
+	urls := l.Info.StreamURL.FlvPullURL
+	var url string
+	for _, u := range []string{urls.FullHd1, urls.Hd1, urls.Sd1, urls.Sd2} {
+		if u != "" {
+			url = u
+			break
+		}
+	}
+	if url == "" {
+		return ErrURLNotFound
+	}
+
+	resp, err := l.getStream(ctx, l.streamClient(), url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+func (l *Live) recordHLS(ctx context.Context, w io.Writer) error {
+	playlist := l.Info.StreamURL.HlsPullURL
+	if playlist == "" {
+		return ErrURLNotFound
+	}
+	c := l.streamClient()
+
+	seen := make(map[string]struct{})
+	lastNew := time.Now()
+	for {
+		p, err := l.getPlaylist(ctx, c, playlist)
+		if err != nil {
+			return err
+		}
+		if len(p.variants) > 0 {
+			// A master playlist, record its best variant instead.
+			playlist = p.variants[0]
+			continue
+		}
+
+		current := make(map[string]struct{}, len(p.segments))
+		for _, segment := range p.segments {
+			current[segment] = struct{}{}
+			if _, ok := seen[segment]; ok {
+				continue
+			}
+			lastNew = time.Now()
+			if err := l.recordSegment(ctx, c, segment, w); err != nil {
+				return err
+			}
+		}
+		// Segments that left the playlist do not come back, only the current ones need to be remembered.
+		seen = current
+		if p.ended || time.Since(lastNew) > hlsStaleTargets*p.target {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(max(p.target/2, 100*time.Millisecond)):
+		}
+	}
+}
+
+// recordSegment writes the segment to w. A segment that cannot be fetched in hlsSegmentAttempts is skipped, leaving a
+// gap in the recording instead of ending it. The segment is fetched completely before it is written, so a failed
+// attempt does not leave part of it behind.
+func (l *Live) recordSegment(ctx context.Context, c *http.Client, url string, w io.Writer) error {
+	var err error
+	for attempt := 1; attempt <= hlsSegmentAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt-1) * hlsSegmentRetry):
+			}
+		}
+		var b []byte
+		b, err = l.getSegment(ctx, c, url)
+		if err == nil {
+			_, err = w.Write(b)
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	l.t.warnHandler(fmt.Errorf("skipping stream segment after %d attempts: %w", hlsSegmentAttempts, err))
+	return nil
+}
+
+func (l *Live) getSegment(ctx context.Context, c *http.Client, url string) ([]byte, error) {
+	resp, err := l.getStream(ctx, c, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// hlsPlaylist is the part of an HLS playlist needed to record it. URLs are absolute, variants are sorted by
+// bandwidth, best first.
+type hlsPlaylist struct {
+	variants []string
+	segments []string
+	target   time.Duration
+	ended    bool
+}
+
+func (l *Live) getPlaylist(ctx context.Context, c *http.Client, playlist string) (*hlsPlaylist, error) {
+	resp, err := l.getStream(ctx, c, playlist)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	base, err := url.Parse(playlist)
+	if err != nil {
+		return nil, err
+	}
+	return parsePlaylist(resp.Body, base)
+}
+
+func parsePlaylist(r io.Reader, base *url.URL) (*hlsPlaylist, error) {
+	p := &hlsPlaylist{target: 2 * time.Second}
+	var bandwidths []int
+	bandwidth := -1
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXT-X-TARGETDURATION:"):
+			if d, err := strconv.ParseFloat(strings.TrimPrefix(line, "#EXT-X-TARGETDURATION:"), 64); err == nil && d > 0 {
+				p.target = time.Duration(d * float64(time.Second))
+			}
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF:"):
+			bandwidth = 0
+			for _, attr := range strings.Split(strings.TrimPrefix(line, "#EXT-X-STREAM-INF:"), ",") {
+				if v, ok := strings.CutPrefix(attr, "BANDWIDTH="); ok {
+					bandwidth, _ = strconv.Atoi(v)
+				}
+			}
+		case line == "#EXT-X-ENDLIST":
+			p.ended = true
+		case strings.HasPrefix(line, "#"):
+		default:
+			u, err := base.Parse(line)
+			if err != nil {
+				return nil, err
+			}
+			if bandwidth < 0 {
+				p.segments = append(p.segments, u.String())
+				continue
+			}
+			// Keep the variants sorted by bandwidth, best first.
+			i := 0
+			for i < len(bandwidths) && bandwidths[i] >= bandwidth {
+				i++
+			}
+			bandwidths = slices.Insert(bandwidths, i, bandwidth)
+			p.variants = slices.Insert(p.variants, i, u.String())
+			bandwidth = -1
+		}
+	}
+	return p, s.Err()
+}
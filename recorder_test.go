@@ -0,0 +1,48 @@
+package gotiktoklive
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlaylist(t *testing.T) {
+	base, _ := url.Parse("https://pull.example.com/stage/stream/index.m3u8?expire=1")
+
+	master, err := parsePlaylist(strings.NewReader(`#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=800000,RESOLUTION=640x360
+sd/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2500000,RESOLUTION=1280x720
+https://cdn.example.com/hd/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=1200000
+/ld/index.m3u8
+`), base)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://cdn.example.com/hd/index.m3u8",
+		"https://pull.example.com/ld/index.m3u8",
+		"https://pull.example.com/stage/stream/sd/index.m3u8",
+	}, master.variants)
+	assert.Empty(t, master.segments)
+
+	media, err := parsePlaylist(strings.NewReader(`#EXTM3U
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:10
+#EXTINF:4.0,
+10.ts?token=a
+#EXTINF:4.0,
+11.ts?token=a
+`), base)
+	require.NoError(t, err)
+	assert.Empty(t, media.variants)
+	assert.Equal(t, []string{
+		"https://pull.example.com/stage/stream/10.ts?token=a",
+		"https://pull.example.com/stage/stream/11.ts?token=a",
+	}, media.segments)
+	assert.Equal(t, 4*time.Second, media.target)
+	assert.False(t, media.ended)
+}
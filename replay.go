@@ -0,0 +1,132 @@
+package gotiktoklive
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	traceTimestampLayout = "2006-01-02 15:04:05.000"
+	traceClosed          = "websocket closed"
+	traceMaxLine         = 64 << 20
+)
+
+// traceFrame is a line of a websocket trace written with EnableWSTrace.
+type traceFrame struct {
+	time      time.Time
+	direction string
+	data      []byte
+	closed    bool
+}
+
+// parseTraceLine parses a line as written by TikTok.writeTrace: a timestamp directly followed by the direction, a space
+// and the hex encoded frame.
+func parseTraceLine(line string) (traceFrame, error) {
+	if len(line) < len(traceTimestampLayout) {
+		return traceFrame{}, fmt.Errorf("trace line too short")
+	}
+	ts, err := time.Parse(traceTimestampLayout, line[:len(traceTimestampLayout)])
+	if err != nil {
+		return traceFrame{}, fmt.Errorf("invalid trace timestamp: %w", err)
+	}
+	rest := line[len(traceTimestampLayout):]
+	if direction, ok := strings.CutSuffix(rest, " "+traceClosed); ok {
+		return traceFrame{time: ts, direction: direction, closed: true}, nil
+	}
+	i := strings.LastIndexByte(rest, ' ')
+	if i < 0 {
+		return traceFrame{}, fmt.Errorf("trace line without direction")
+	}
+	data, err := hex.DecodeString(rest[i+1:])
+	if err != nil {
+		return traceFrame{}, fmt.Errorf("invalid trace frame: %w", err)
+	}
+	return traceFrame{time: ts, direction: rest[:i], data: data}, nil
+}
+
+// ReplayTrace plays back a websocket trace written with EnableWSTrace without connecting to TikTok. The frames
+// received from TikTok are parsed like those of a tracked live and sent to the Events channel and handlers of the
+// returned Live, which is closed once the whole trace was replayed. Frames sent by the client are skipped.
+//
+// The options configure the replay like those of NewTikTok, use ReplaySpeed to change the playback speed. No requests
+// are made, so the returned Live has no Info or GiftInfo and its ID is empty.
+func ReplayTrace(path string, options ...TikTokLiveOption) (*Live, error) {
+	t := newTikTok("", "")
+	for _, option := range options {
+		if err := option(t); err != nil {
+			t.cancel()
+			return nil, err
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.cancel()
+		return nil, err
+	}
+
+	live := t.newLive("")
+	live.replay = true
+	live.wg.Add(1)
+	go func() {
+		defer live.wg.Done()
+		// The TikTok instance only exists for this replay.
+		defer t.cancel()
+		defer live.closeEvents()
+		defer live.stopHandlers()
+		defer f.Close()
+		live.replayTrace(f)
+	}()
+	return live, nil
+}
+
+func (l *Live) replayTrace(f *os.File) {
+	defer l.finish()
+	defer l.cancel()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, traceMaxLine)
+	var last time.Time
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		frame, err := parseTraceLine(line)
+		if err != nil {
+			l.t.errHandler(fmt.Errorf("cannot replay line %d of %s: %w", n, f.Name(), err))
+			continue
+		}
+		if frame.direction != "<=" || frame.closed {
+			continue
+		}
+
+		if !last.IsZero() && l.t.replaySpeed > 0 {
+			delay := time.Duration(float64(frame.time.Sub(last)) / l.t.replaySpeed)
+			if delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-l.done():
+					return
+				}
+			}
+		}
+		last = frame.time
+
+		if err := l.parseWssMsg(frame.data); err != nil {
+			l.t.errHandler(fmt.Errorf("Failed to parse websocket message: %w", err))
+		}
+
+		select {
+		case <-l.done():
+			return
+		default:
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		l.t.errHandler(fmt.Errorf("cannot read trace %s: %w", f.Name(), err))
+	}
+}
@@ -0,0 +1,99 @@
+package gotiktoklive
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// traceLine encodes chat messages as a frame in the format written by EnableWSTrace.
+func traceLine(t *testing.T, at time.Time, msgID int64, comments ...string) string {
+	response := &pb.WebcastResponse{NeedsAck: true}
+	for i, comment := range comments {
+		payload, err := proto.Marshal(&pb.WebcastChatMessage{
+			Common:  &pb.Common{MsgId: msgID + int64(i), CreateTime: at.UnixMilli()},
+			User:    &pb.User{Id: 1},
+			Content: comment,
+		})
+		require.NoError(t, err)
+		response.Messages = append(response.Messages, &pb.WebcastResponse_Message{
+			Method:  "WebcastChatMessage",
+			Payload: payload,
+		})
+	}
+	payload, err := proto.Marshal(response)
+	require.NoError(t, err)
+	frame, err := proto.Marshal(&pb.WebcastPushFrame{PayloadType: "msg", Payload: payload})
+	require.NoError(t, err)
+	return at.UTC().Format(traceTimestampLayout) + "<= " + hex.EncodeToString(frame) + "\n"
+}
+
+func TestReplayTrace(t *testing.T) {
+	start := time.Now()
+	trace := strings.Join([]string{
+		traceLine(t, start, 9001, "first", "second"),
+		start.UTC().Format(traceTimestampLayout) + "=> 3a026862\n",
+		start.UTC().Format(traceTimestampLayout) + "<= (unexpected opcode 02) 00\n",
+		"garbage\n",
+		traceLine(t, start.Add(time.Hour), 9003, "third"),
+		start.Add(time.Hour).UTC().Format(traceTimestampLayout) + "<= websocket closed\n",
+	}, "")
+	path := filepath.Join(t.TempDir(), "trace.log")
+	require.NoError(t, os.WriteFile(path, []byte(trace), 0o644))
+
+	live, err := ReplayTrace(path, ReplaySpeed(0))
+	require.NoError(t, err)
+	defer live.Close()
+
+	var comments []string
+	disconnected := false
+	timeout := time.After(5 * time.Second)
+	for !disconnected {
+		select {
+		case e, ok := <-live.Events:
+			if !ok {
+				t.Fatal("events closed before disconnect")
+			}
+			switch e := e.(type) {
+			case ChatEvent:
+				comments = append(comments, e.Comment)
+			case *DisconnectEvent:
+				disconnected = true
+			}
+		case <-timeout:
+			t.Fatal("replay did not finish")
+		}
+	}
+	assert.Equal(t, []string{"first", "second", "third"}, comments)
+	assert.Equal(t, 3, live.Stats().Chats)
+
+	// The TikTok instance of the replay is canceled once it is done.
+	select {
+	case <-live.t.done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("replay TikTok instance not canceled")
+	}
+}
+
+func TestParseTraceLine(t *testing.T) {
+	frame, err := parseTraceLine("2024-01-02 03:04:05.678<= (unexpected opcode 02) 0aff")
+	require.NoError(t, err)
+	assert.Equal(t, "<= (unexpected opcode 02)", frame.direction)
+	assert.Equal(t, []byte{0x0a, 0xff}, frame.data)
+	assert.Equal(t, 678*time.Millisecond, time.Duration(frame.time.Nanosecond()))
+
+	frame, err = parseTraceLine("2024-01-02 03:04:05.678<= websocket closed")
+	require.NoError(t, err)
+	assert.True(t, frame.closed)
+
+	_, err = parseTraceLine("2024-01-02 03:04:05.678<= zz")
+	assert.Error(t, err)
+}
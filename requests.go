@@ -3,6 +3,7 @@ package gotiktoklive
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -41,7 +42,7 @@ type reqOptions struct {
 	ExtraTikTokCookies string
 }
 
-func (t *TikTok) sendRequest(o *reqOptions, customValidate func(response *http.Response) error) ([]byte, http.Header, error) {
+func (t *TikTok) sendRequest(ctx context.Context, o *reqOptions, customValidate func(response *http.Response) error) ([]byte, http.Header, error) {
 	var err error
 
 	defer func() {
@@ -54,9 +55,9 @@ func (t *TikTok) sendRequest(o *reqOptions, customValidate func(response *http.R
 		method = "POST"
 	}
 
-	uri := tiktokAPIUrl
+	uri := t.apiUrl
 	if o.OmitAPI {
-		uri = tiktokBaseUrl
+		uri = t.baseUrl
 	}
 	if o.URI != "" {
 		uri = o.URI
@@ -86,11 +87,11 @@ func (t *TikTok) sendRequest(o *reqOptions, customValidate func(response *http.R
 	fullUrl := u.String()
 	if !o.OmitAPI && o.URI == "" && o.Endpoint == urlRoomData {
 		t.debugHandler("signing for url ", fullUrl)
-		return t.signURL(fullUrl, o)
+		return t.signURL(ctx, fullUrl, o)
 	}
 
 	var req *http.Request
-	req, err = http.NewRequest(method, fullUrl, reqData)
+	req, err = http.NewRequestWithContext(ctx, method, fullUrl, reqData)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,141 @@
+package gotiktoklive
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+)
+
+// DefaultCreatorShare is the part of the value of the gifts a creator is estimated to be paid out.
+const DefaultCreatorShare = 0.5
+
+// RevenueEstimator converts diamonds into money using the prices of the coin packs of a PriceList. The price of a
+// coin is averaged over all packs, so it is only an estimate of what viewers paid.
+type RevenueEstimator struct {
+	// Currency is the local currency to estimate in, defaults to the local currency of the price list.
+	Currency string
+	// CreatorShare is the part of the value paid out to the creator, between 0 and 1. Defaults to
+	// DefaultCreatorShare.
+	CreatorShare float64
+
+	// usd and local are the price of a coin in cents.
+	usd   float64
+	local map[string]float64
+}
+
+// Revenue is the estimated value of diamonds. Gross is what the viewers paid for the coins, USD and Local the creator
+// share of it. Local is 0 if the price list has no prices in Currency.
+type Revenue struct {
+	Diamonds   int
+	Currency   string
+	GrossUSD   float64
+	GrossLocal float64
+	USD        float64
+	Local      float64
+}
+
+// Add returns the sum of both revenues.
+func (r Revenue) Add(o Revenue) Revenue {
+	r.Diamonds += o.Diamonds
+	r.GrossUSD += o.GrossUSD
+	r.GrossLocal += o.GrossLocal
+	r.USD += o.USD
+	r.Local += o.Local
+	return r
+}
+
+// NewRevenueEstimator creates a RevenueEstimator from a price list, see TikTok.GetPriceList and ReadPriceList.
+func NewRevenueEstimator(prices *PriceList) (*RevenueEstimator, error) {
+	if prices == nil {
+		return nil, errors.New("no price list")
+	}
+	var coins, usd int
+	localCoins := make(map[string]int)
+	localCents := make(map[string]int)
+	currency := strings.ToUpper(prices.Extra.DefaultCurrency)
+	for _, item := range prices.PriceList {
+		if item == nil || item.DiamondCount <= 0 {
+			continue
+		}
+		if item.Price > 0 {
+			coins += item.DiamondCount
+			usd += item.Price
+		}
+		if item.ExchangePrice > 0 && currency != "" {
+			localCoins[currency] += item.DiamondCount
+			localCents[currency] += item.ExchangePrice
+		}
+		for _, p := range item.CurrencyPrice {
+			c := strings.ToUpper(p.Currency)
+			if p.Price <= 0 || c == "" || (c == currency && item.ExchangePrice > 0) {
+				continue
+			}
+			localCoins[c] += item.DiamondCount
+			localCents[c] += p.Price
+		}
+	}
+	if coins == 0 {
+		return nil, errors.New("price list has no coin prices")
+	}
+
+	r := &RevenueEstimator{
+		Currency:     currency,
+		CreatorShare: DefaultCreatorShare,
+		usd:          float64(usd) / float64(coins),
+		local:        make(map[string]float64),
+	}
+	if r.Currency == "" {
+		r.Currency = "USD"
+	}
+	for c, n := range localCoins {
+		r.local[c] = float64(localCents[c]) / float64(n)
+	}
+	if _, ok := r.local["USD"]; !ok {
+		r.local["USD"] = r.usd
+	}
+	return r, nil
+}
+
+// ReadPriceList reads a price list saved as JSON, such as the response of TikTok.GetPriceList encoded with
+// json.Marshal. It allows estimating revenue offline.
+func ReadPriceList(r io.Reader) (*PriceList, error) {
+	var prices PriceList
+	if err := json.NewDecoder(r).Decode(&prices); err != nil {
+		return nil, err
+	}
+	return &prices, nil
+}
+
+// Currencies returns the local currencies the price list has prices in.
+func (r *RevenueEstimator) Currencies() []string {
+	var currencies []string
+	for c := range r.local {
+		currencies = append(currencies, c)
+	}
+	slices.Sort(currencies)
+	return currencies
+}
+
+// Estimate returns the estimated value of diamonds.
+func (r *RevenueEstimator) Estimate(diamonds int) Revenue {
+	share := min(max(r.CreatorShare, 0), 1)
+	currency := strings.ToUpper(r.Currency)
+	rev := Revenue{
+		Diamonds:   diamonds,
+		Currency:   currency,
+		GrossUSD:   float64(diamonds) * r.usd / 100,
+		GrossLocal: float64(diamonds) * r.local[currency] / 100,
+	}
+	rev.USD = rev.GrossUSD * share
+	rev.Local = rev.GrossLocal * share
+	return rev
+}
+
+// Gift returns the estimated value of a gift event, its diamonds times its repeat count. Combo gifts are sent for
+// every step of the streak with a running count, only pass the last one or use GiftStreakEndEvent and
+// LiveStats.Diamonds to not count a streak more than once.
+func (r *RevenueEstimator) Gift(e GiftEvent) Revenue {
+	return r.Estimate(e.Diamonds * max(e.RepeatCount, 1))
+}
@@ -0,0 +1,56 @@
+package gotiktoklive
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const priceListSnapshot = `{
+	"data": [
+		{"id": 1, "diamond_count": 70, "price": 74, "exchange_price": 70,
+		 "currency_price": [{"currency": "eur", "price": 70}, {"currency": "gbp", "price": 60}]},
+		{"id": 2, "diamond_count": 350, "price": 370, "exchange_price": 350,
+		 "currency_price": [{"currency": "eur", "price": 350}, {"currency": "gbp", "price": 300}]},
+		{"id": 3, "diamond_count": 0, "price": 100}
+	],
+	"extra": {"default_currency": "eur"},
+	"status_code": 0
+}`
+
+func TestRevenueEstimator(t *testing.T) {
+	prices, err := ReadPriceList(strings.NewReader(priceListSnapshot))
+	require.NoError(t, err)
+	r, err := NewRevenueEstimator(prices)
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", r.Currency)
+	assert.Equal(t, []string{"EUR", "GBP", "USD"}, r.Currencies())
+
+	// 420 coins cost 444 US cents and 420 euro cents.
+	rev := r.Estimate(420)
+	assert.Equal(t, 420, rev.Diamonds)
+	assert.Equal(t, "EUR", rev.Currency)
+	assert.InDelta(t, 4.44, rev.GrossUSD, 1e-9)
+	assert.InDelta(t, 4.20, rev.GrossLocal, 1e-9)
+	assert.InDelta(t, 2.22, rev.USD, 1e-9)
+	assert.InDelta(t, 2.10, rev.Local, 1e-9)
+
+	r.CreatorShare = 0.25
+	r.Currency = "gbp"
+	rev = r.Gift(GiftEvent{Diamonds: 12, RepeatCount: 35, RepeatEnd: true})
+	assert.Equal(t, 420, rev.Diamonds)
+	assert.InDelta(t, 3.60, rev.GrossLocal, 1e-9)
+	assert.InDelta(t, 0.90, rev.Local, 1e-9)
+	assert.InDelta(t, 1.11, rev.USD, 1e-9)
+
+	total := r.Gift(GiftEvent{Diamonds: 1}).Add(rev)
+	assert.Equal(t, 421, total.Diamonds)
+
+	r.Currency = "JPY"
+	assert.Zero(t, r.Estimate(100).Local)
+
+	_, err = NewRevenueEstimator(&PriceList{})
+	assert.Error(t, err)
+}
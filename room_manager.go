@@ -0,0 +1,354 @@
+package gotiktoklive
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+)
+
+const (
+	defaultManagerPollInterval  = 2 * time.Minute
+	defaultManagerConcurrency   = 4
+	defaultManagerEventChanSize = 1000
+)
+
+// RoomManager tracks a set of users and rooms at once. It periodically checks if they are live, starts tracking them
+// when they are, and fans the events of all tracked lives into one channel. Lives are torn down when their stream
+// ends and picked up again on the next poll once they go live again.
+//
+// All lives share the signing rate limit of the TikTok instance the manager was created with.
+type RoomManager struct {
+	t *TikTok
+
+	// Events receives the events of all tracked lives tagged with the room they came from. It is closed after Close.
+	Events chan ManagedEvent
+
+	interval    time.Duration
+	concurrency int
+	chanSize    int
+
+	targets map[string]*managedRoom
+	mu      sync.Mutex
+	wake    chan struct{}
+
+	// rooms maps the room IDs being connected to or tracked to the target that claimed them.
+	rooms map[string]*managedRoom
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// ManagedEvent is an event of a live tracked by a RoomManager.
+type ManagedEvent struct {
+	RoomID   string
+	Username string
+	Event    Event
+}
+
+type managedRoom struct {
+	username   string
+	roomID     string
+	live       *Live
+	connecting bool
+	removed    bool
+	claimed    string
+}
+
+// RoomManagerOption configures a RoomManager created with TikTok.NewRoomManager.
+type RoomManagerOption func(m *RoomManager) error
+
+// ManagerPollInterval sets how often users and rooms that are not being tracked are checked for going live.
+// Defaults to 2 minutes.
+func ManagerPollInterval(interval time.Duration) RoomManagerOption {
+	return func(m *RoomManager) error {
+		if interval <= 0 {
+			return fmt.Errorf("invalid poll interval %s", interval)
+		}
+		m.interval = interval
+		return nil
+	}
+}
+
+// ManagerConcurrency sets how many users or rooms are checked and connected to at the same time. Defaults to 4.
+func ManagerConcurrency(n int) RoomManagerOption {
+	return func(m *RoomManager) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid concurrency %d", n)
+		}
+		m.concurrency = n
+		return nil
+	}
+}
+
+// ManagerEventsChanSize sets the buffer size of RoomManager.Events. Defaults to 1000.
+func ManagerEventsChanSize(size int) RoomManagerOption {
+	return func(m *RoomManager) error {
+		if size < 0 {
+			return fmt.Errorf("invalid events channel size %d", size)
+		}
+		m.chanSize = size
+		return nil
+	}
+}
+
+// NewRoomManager creates a RoomManager and starts polling. Add users or rooms to track with AddUser and AddRoom.
+func (t *TikTok) NewRoomManager(options ...RoomManagerOption) (*RoomManager, error) {
+	m := &RoomManager{
+		t:           t,
+		interval:    defaultManagerPollInterval,
+		concurrency: defaultManagerConcurrency,
+		chanSize:    defaultManagerEventChanSize,
+		targets:     make(map[string]*managedRoom),
+		rooms:       make(map[string]*managedRoom),
+		wake:        make(chan struct{}, 1),
+	}
+	for _, option := range options {
+		if err := option(m); err != nil {
+			return nil, err
+		}
+	}
+	m.Events = make(chan ManagedEvent, m.chanSize)
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.pollLoop()
+	}()
+	return m, nil
+}
+
+// AddUser starts watching a user. The user is checked right away and tracked once live.
+func (m *RoomManager) AddUser(username string) {
+	username = cleanupUser(username)
+	m.add("user:"+username, &managedRoom{username: username})
+}
+
+// AddRoom starts watching a room by room ID. The room is checked right away and tracked once live.
+func (m *RoomManager) AddRoom(roomID string) {
+	m.add("room:"+roomID, &managedRoom{roomID: roomID})
+}
+
+// RemoveUser stops watching a user and closes its live if it is being tracked.
+func (m *RoomManager) RemoveUser(username string) {
+	m.remove("user:" + cleanupUser(username))
+}
+
+// RemoveRoom stops watching a room and closes its live if it is being tracked.
+func (m *RoomManager) RemoveRoom(roomID string) {
+	m.remove("room:" + roomID)
+}
+
+// Lives returns the lives currently being tracked keyed by room ID.
+func (m *RoomManager) Lives() map[string]*Live {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lives := make(map[string]*Live)
+	for _, r := range m.targets {
+		if r.live != nil {
+			lives[r.live.ID] = r.live
+		}
+	}
+	return lives
+}
+
+// Close stops polling, closes all tracked lives and closes the Events channel.
+func (m *RoomManager) Close() {
+	m.once.Do(func() {
+		m.cancel()
+		m.mu.Lock()
+		var lives []*Live
+		for _, r := range m.targets {
+			r.removed = true
+			if r.live != nil {
+				lives = append(lives, r.live)
+			}
+		}
+		m.mu.Unlock()
+		for _, l := range lives {
+			l.Close()
+		}
+		m.wg.Wait()
+		close(m.Events)
+	})
+}
+
+func (m *RoomManager) add(key string, r *managedRoom) {
+	m.mu.Lock()
+	if _, ok := m.targets[key]; !ok {
+		m.targets[key] = r
+	}
+	m.mu.Unlock()
+
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (m *RoomManager) remove(key string) {
+	m.mu.Lock()
+	var live *Live
+	if r, ok := m.targets[key]; ok {
+		r.removed = true
+		live = r.live
+		delete(m.targets, key)
+	}
+	m.mu.Unlock()
+
+	if live != nil {
+		live.Close()
+	}
+}
+
+func (m *RoomManager) pollLoop() {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.poll()
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-m.t.done():
+			return
+		case <-ticker.C:
+		case <-m.wake:
+		}
+	}
+}
+
+// poll checks every target that is not being tracked and connects to the ones that are live.
+func (m *RoomManager) poll() {
+	m.mu.Lock()
+	var pending []*managedRoom
+	for _, r := range m.targets {
+		if r.live == nil && !r.connecting {
+			r.connecting = true
+			pending = append(pending, r)
+		}
+	}
+	m.mu.Unlock()
+
+	sem := make(chan struct{}, m.concurrency)
+	wg := sync.WaitGroup{}
+	for _, r := range pending {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(r *managedRoom) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			m.check(r)
+		}(r)
+	}
+	wg.Wait()
+}
+
+func (m *RoomManager) check(r *managedRoom) {
+	live, err := m.track(r)
+
+	m.mu.Lock()
+	r.connecting = false
+	if err != nil || live == nil || r.removed {
+		m.release(r)
+		m.mu.Unlock()
+		if err != nil {
+			m.t.debugHandler(fmt.Sprintf("room manager: not tracking %s: %s", r, err))
+		}
+		if live != nil {
+			live.Close()
+		}
+		return
+	}
+	r.live = live
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.forward(r, live)
+	}()
+}
+
+// track connects to the target if it is live. It returns a nil live if the target is offline.
+func (m *RoomManager) track(r *managedRoom) (*Live, error) {
+	roomID := r.roomID
+	info := LiveRoomUserInfo{LiveRoomUser: &LiveRoomUser{RoomID: roomID}}
+	if r.username != "" {
+		var err error
+		info, err = m.t.GetLiveRoomUserInfoContext(m.ctx, r.username)
+		if err != nil {
+			return nil, err
+		}
+		roomID = info.LiveRoomUser.RoomID
+		if roomID == "" {
+			return nil, nil
+		}
+	}
+	// A user and a room, or two users, can point to the same room, which is only tracked once.
+	m.mu.Lock()
+	other, ok := m.rooms[roomID]
+	if !ok {
+		m.rooms[roomID] = r
+		r.claimed = roomID
+	}
+	m.mu.Unlock()
+	if ok {
+		return nil, fmt.Errorf("room %s is already tracked for %s", roomID, other)
+	}
+
+	isLive, err := m.t.IsLiveContext(m.ctx, info)
+	if err != nil || !isLive {
+		return nil, err
+	}
+	return m.t.TrackRoomContext(m.ctx, roomID)
+}
+
+// release frees the room claimed by the target for other targets. m.mu must be held.
+func (m *RoomManager) release(r *managedRoom) {
+	if r.claimed != "" {
+		delete(m.rooms, r.claimed)
+		r.claimed = ""
+	}
+}
+
+// forward passes the events of a live to the manager until the live ends, then marks the target as not tracked so the
+// next poll can pick it up again.
+func (m *RoomManager) forward(r *managedRoom, live *Live) {
+	username := r.username
+	if username == "" && live.Info != nil && live.Info.Owner != nil {
+		username = live.Info.Owner.Username
+	}
+
+	for e := range live.Events {
+		select {
+		case m.Events <- ManagedEvent{RoomID: live.ID, Username: username, Event: e}:
+		case <-m.ctx.Done():
+		}
+
+		if c, ok := e.(ControlEvent); ok &&
+			(pb.ControlAction(c.Action) == pb.ControlAction_STREAM_ENDED ||
+				pb.ControlAction(c.Action) == pb.ControlAction_STREAM_ENDED_BAN) {
+			m.t.infoHandler(fmt.Sprintf("room manager: stream of %s ended", r))
+			break
+		}
+	}
+	live.Close()
+
+	m.mu.Lock()
+	r.live = nil
+	m.release(r)
+	m.mu.Unlock()
+}
+
+func (r *managedRoom) String() string {
+	if r.username != "" {
+		return "@" + r.username
+	}
+	return "room " + r.roomID
+}
@@ -0,0 +1,112 @@
+package gotiktoklive_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/steampoweredtaco/gotiktoklive"
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/steampoweredtaco/gotiktoklive/tiktoktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func nextManagedEvent[T gotiktoklive.Event](t *testing.T, m *gotiktoklive.RoomManager) (gotiktoklive.ManagedEvent, T) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-m.Events:
+			if !ok {
+				t.Fatal("events closed")
+			}
+			if ev, ok := e.Event.(T); ok {
+				return e, ev
+			}
+		case <-timeout:
+			var zero T
+			t.Fatalf("no %T received", zero)
+		}
+	}
+}
+
+func TestRoomManager(t *testing.T) {
+	srv := tiktoktest.NewServer()
+	defer srv.Close()
+	room := srv.AddRoom("tester", "7000000000000000004")
+
+	tiktok := newServerTikTok(t, srv)
+	manager, err := tiktok.NewRoomManager(gotiktoklive.ManagerPollInterval(20 * time.Millisecond))
+	require.NoError(t, err)
+
+	// The user is checked right away and tracked once live.
+	manager.AddUser("@tester")
+	require.Eventually(t, func() bool { return len(manager.Lives()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, manager.Lives(), room.ID)
+
+	require.NoError(t, room.Push(&pb.WebcastChatMessage{Common: &pb.Common{MsgId: 8000000000000000021}, Content: "first"}))
+	e, chat := nextManagedEvent[gotiktoklive.ChatEvent](t, manager)
+	assert.Equal(t, room.ID, e.RoomID)
+	assert.Equal(t, "tester", e.Username)
+	assert.Equal(t, "first", chat.Comment)
+
+	// Once the stream ends the live is torn down.
+	require.NoError(t, room.Push(&pb.WebcastControlMessage{
+		Common: &pb.Common{MsgId: 8000000000000000022},
+		Action: pb.ControlAction_STREAM_ENDED,
+	}))
+	nextManagedEvent[gotiktoklive.ControlEvent](t, manager)
+	require.Eventually(t, func() bool { return room.Connects() == 2 }, 5*time.Second, 10*time.Millisecond,
+		"the room is still live and picked up again on the next poll")
+	require.Eventually(t, func() bool { return len(manager.Lives()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, room.Push(&pb.WebcastChatMessage{Common: &pb.Common{MsgId: 8000000000000000023}, Content: "again"}))
+	_, chat = nextManagedEvent[gotiktoklive.ChatEvent](t, manager)
+	assert.Equal(t, "again", chat.Comment)
+
+	// A removed room that goes live again is not tracked.
+	live := manager.Lives()[room.ID]
+	manager.RemoveUser("tester")
+	assert.Empty(t, manager.Lives())
+	for range live.Events {
+	}
+
+	manager.AddRoom(room.ID)
+	require.Eventually(t, func() bool { return len(manager.Lives()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// Close stops polling, closes the lives and the events channel.
+	manager.Close()
+	assert.Empty(t, manager.Lives())
+	connects := room.Connects()
+	for range manager.Events {
+	}
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, connects, room.Connects())
+}
+
+func TestRoomManagerSameRoom(t *testing.T) {
+	srv := tiktoktest.NewServer()
+	defer srv.Close()
+	room := srv.AddRoom("tester", "7000000000000000007")
+
+	tiktok := newServerTikTok(t, srv)
+	manager, err := tiktok.NewRoomManager(gotiktoklive.ManagerPollInterval(20 * time.Millisecond))
+	require.NoError(t, err)
+	defer manager.Close()
+
+	// The user and the room are the same live, it is only connected to once.
+	manager.AddUser("tester")
+	manager.AddRoom(room.ID)
+	require.Eventually(t, func() bool { return len(manager.Lives()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, room.Connects())
+
+	require.NoError(t, room.Push(&pb.WebcastChatMessage{Common: &pb.Common{MsgId: 8000000000000000024}, Content: "once"}))
+	_, chat := nextManagedEvent[gotiktoklive.ChatEvent](t, manager)
+	assert.Equal(t, "once", chat.Comment)
+	select {
+	case e := <-manager.Events:
+		t.Errorf("unexpected second event %T", e.Event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
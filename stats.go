@@ -0,0 +1,199 @@
+package gotiktoklive
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	statsTopGifters = 10
+)
+
+// LiveStats is a snapshot of the statistics of a live, see Live.Stats.
+type LiveStats struct {
+	RoomID    string    `json:"room_id"`
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Events is the number of events the statistics were computed from.
+	Events int `json:"events"`
+
+	// Diamonds is the value of all gifts received. Combo gifts are counted once their streak ends.
+	Diamonds int `json:"diamonds"`
+	// Gifts is the number of gifts received, a combo of five roses counts as five.
+	Gifts      int      `json:"gifts"`
+	Gifters    int      `json:"gifters"`
+	TopGifters []Gifter `json:"top_gifters"`
+	Chats      int      `json:"chats"`
+	Chatters   int      `json:"chatters"`
+
+	// Viewers is the last viewer count, AvgViewers the average of all viewer counts received.
+	Viewers     int     `json:"viewers"`
+	PeakViewers int     `json:"peak_viewers"`
+	AvgViewers  float64 `json:"avg_viewers"`
+
+	// TotalLikes is the like count of the room as reported by TikTok, including likes from before connecting.
+	TotalLikes int `json:"total_likes"`
+	Joins      int `json:"joins"`
+	Follows    int `json:"follows"`
+	Shares     int `json:"shares"`
+	Subscribes int `json:"subscribes"`
+
+	// Chests is the number of treasure chests dropped, ChestDiamonds their total value.
+	Chests        int `json:"chests"`
+	ChestDiamonds int `json:"chest_diamonds"`
+}
+
+// Gifter is a user in LiveStats.TopGifters.
+type Gifter struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+	Diamonds int    `json:"diamonds"`
+	Gifts    int    `json:"gifts"`
+}
+
+// statsTracker accumulates LiveStats from the events of a live.
+type statsTracker struct {
+	mu             sync.Mutex
+	stats          LiveStats
+	excludeHistory bool
+	// streaks is set when gift streaks are enabled, diamonds are then taken from GiftStreakEndEvent.
+	streaks      bool
+	gifters      map[string]*Gifter
+	chatters     map[string]struct{}
+	envelopes    map[string]struct{}
+	viewersTotal int
+	viewersCount int
+}
+
+func newStatsTracker(roomID string, excludeHistory, streaks bool) *statsTracker {
+	now := time.Now()
+	return &statsTracker{
+		stats: LiveStats{
+			RoomID:    roomID,
+			StartedAt: now,
+			UpdatedAt: now,
+		},
+		excludeHistory: excludeHistory,
+		streaks:        streaks,
+		gifters:        make(map[string]*Gifter),
+		chatters:       make(map[string]struct{}),
+		envelopes:      make(map[string]struct{}),
+	}
+}
+
+func (s *statsTracker) process(e Event) []Event {
+	if s.excludeHistory && e.IsHistory() {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch e := e.(type) {
+	case GiftEvent:
+		if s.streaks {
+			return nil
+		}
+		// Combo gifts are sent for every step of the streak with a running count, only the last one is final.
+		if (e.Type == 1 || e.IsComboGift) && !e.RepeatEnd {
+			return nil
+		}
+		s.addGift(e, max(e.RepeatCount, 1))
+	case GiftStreakEndEvent:
+		if !s.streaks {
+			return nil
+		}
+		s.addGift(e.Gift, e.Count)
+	case ChatEvent:
+		s.stats.Chats++
+		// Users without any identity cannot be told apart and are not counted as chatters.
+		if key := e.User.Key(); key != "" {
+			s.chatters[key] = struct{}{}
+			s.stats.Chatters = len(s.chatters)
+		}
+	case ViewersEvent:
+		s.stats.Viewers = e.Viewers
+		s.stats.PeakViewers = max(s.stats.PeakViewers, e.Viewers)
+		s.viewersTotal += e.Viewers
+		s.viewersCount++
+		s.stats.AvgViewers = float64(s.viewersTotal) / float64(s.viewersCount)
+	case LikeEvent:
+		s.stats.TotalLikes = max(s.stats.TotalLikes, e.TotalLikes)
+	case UserEvent:
+		switch e.Event {
+		case USER_JOIN:
+			s.stats.Joins++
+		case USER_FOLLOW:
+			s.stats.Follows++
+		case USER_SHARE:
+			s.stats.Shares++
+		}
+	case SubscribeEvent:
+		s.stats.Subscribes++
+	case EnvelopeEvent:
+		// The same chest is announced again when it is hidden. Chests without an ID cannot be told apart.
+		if e.EnvelopeID != "" {
+			if _, ok := s.envelopes[e.EnvelopeID]; ok {
+				return nil
+			}
+			s.envelopes[e.EnvelopeID] = struct{}{}
+		}
+		s.stats.Chests++
+		s.stats.ChestDiamonds += e.Diamonds
+	default:
+		return nil
+	}
+	s.stats.Events++
+	s.stats.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *statsTracker) addGift(e GiftEvent, count int) {
+	s.stats.Gifts += count
+	s.stats.Diamonds += e.Diamonds * count
+	// Gifts of users without any identity only count towards the totals.
+	if e.User.Key() == "" {
+		return
+	}
+	g, ok := s.gifters[e.User.Key()]
+	if !ok {
+		g = &Gifter{UserID: e.User.ID}
+		s.gifters[e.User.Key()] = g
+		s.stats.Gifters = len(s.gifters)
+	}
+	g.Username = e.User.Username
+	g.Nickname = e.User.Nickname
+	g.Diamonds += e.Diamonds * count
+	g.Gifts += count
+}
+
+func (s *statsTracker) snapshot() LiveStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	stats.TopGifters = make([]Gifter, 0, len(s.gifters))
+	for _, g := range s.gifters {
+		stats.TopGifters = append(stats.TopGifters, *g)
+	}
+	sort.Slice(stats.TopGifters, func(i, j int) bool {
+		a, b := stats.TopGifters[i], stats.TopGifters[j]
+		if a.Diamonds != b.Diamonds {
+			return a.Diamonds > b.Diamonds
+		}
+		return a.UserID < b.UserID
+	})
+	if len(stats.TopGifters) > statsTopGifters {
+		stats.TopGifters = stats.TopGifters[:statsTopGifters]
+	}
+	return stats
+}
+
+// Stats returns a snapshot of the statistics of the live so far. It is safe to call at any time, also after the live
+// has been closed. Use StatsExcludeHistory to leave out the history events TikTok sends on every (re)connect.
+func (l *Live) Stats() LiveStats {
+	return l.stats.snapshot()
+}
@@ -0,0 +1,144 @@
+package gotiktoklive
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	tiktok := newTestTikTok(t)
+	tiktok.statsExcludeHistory = true
+	live := newTestLive(t, tiktok)
+
+	alice := &User{ID: 1, Username: "alice"}
+	bob := &User{ID: 2, Username: "bob"}
+
+	live.emit(ChatEvent{Comment: "hi", User: alice})
+	live.emit(ChatEvent{Comment: "hi again", User: alice})
+	live.emit(ChatEvent{Comment: "hello", User: bob})
+	live.emit(ChatEvent{Comment: "old", User: &User{ID: 3}, eventMeta: eventMeta{isHistory: true}})
+
+	live.emit(GiftEvent{Diamonds: 1, Type: 1, RepeatCount: 1, User: alice})
+	live.emit(GiftEvent{Diamonds: 1, Type: 1, RepeatCount: 3, User: alice})
+	live.emit(GiftEvent{Diamonds: 1, Type: 1, RepeatCount: 3, RepeatEnd: true, User: alice})
+	live.emit(GiftEvent{Diamonds: 99, Type: 2, RepeatCount: 1, User: bob})
+
+	live.emit(ViewersEvent{Viewers: 10})
+	live.emit(ViewersEvent{Viewers: 30})
+	live.emit(ViewersEvent{Viewers: 20})
+	live.emit(LikeEvent{Likes: 5, TotalLikes: 500})
+	live.emit(UserEvent{Event: USER_FOLLOW, User: bob})
+	live.emit(UserEvent{Event: USER_SHARE, User: bob})
+	live.emit(UserEvent{Event: USER_JOIN, User: alice})
+
+	stats := live.Stats()
+	assert.Equal(t, 102, stats.Diamonds)
+	assert.Equal(t, 4, stats.Gifts)
+	assert.Equal(t, 3, stats.Chats)
+	assert.Equal(t, 2, stats.Chatters)
+	if assert.Len(t, stats.TopGifters, 2) {
+		assert.Equal(t, "bob", stats.TopGifters[0].Username)
+		assert.Equal(t, 3, stats.TopGifters[1].Diamonds)
+	}
+	assert.Equal(t, 20, stats.Viewers)
+	assert.Equal(t, 30, stats.PeakViewers)
+	assert.Equal(t, 20.0, stats.AvgViewers)
+	assert.Equal(t, 500, stats.TotalLikes)
+	assert.Equal(t, 1, stats.Follows)
+	assert.Equal(t, 1, stats.Shares)
+	assert.Equal(t, 1, stats.Joins)
+
+	raw, err := json.Marshal(stats)
+	assert.NoError(t, err)
+	var decoded LiveStats
+	assert.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, stats.Diamonds, decoded.Diamonds)
+	assert.Equal(t, stats.TopGifters, decoded.TopGifters)
+}
+
+func TestStatsGiftStreaks(t *testing.T) {
+	tiktok := newTestTikTok(t)
+	tiktok.giftStreakTimeout = time.Hour
+	live := newTestLive(t, tiktok)
+
+	// With gift streaks enabled, streaks are counted when they end, even without a final gift.
+	live.emit(GiftEvent{Diamonds: 5, Type: 1, RepeatCount: 1})
+	live.emit(GiftEvent{Diamonds: 5, Type: 1, RepeatCount: 2})
+	assert.Equal(t, 0, live.Stats().Diamonds)
+
+	live.endGiftStreaks()
+	assert.Equal(t, 10, live.Stats().Diamonds)
+}
+
+func TestStatsHistoryGifts(t *testing.T) {
+	for _, streaks := range []bool{false, true} {
+		for _, excludeHistory := range []bool{false, true} {
+			tiktok := newTestTikTok(t)
+			tiktok.statsExcludeHistory = excludeHistory
+			if streaks {
+				tiktok.giftStreakTimeout = time.Hour
+			}
+			live := newTestLive(t, tiktok)
+
+			// History gifts count the same with and without gift streaks.
+			history := eventMeta{isHistory: true}
+			live.emit(GiftEvent{ID: 1, Diamonds: 5, Type: 1, RepeatCount: 2, RepeatEnd: true, User: &User{ID: 1}, eventMeta: history})
+			live.emit(GiftEvent{ID: 2, Diamonds: 20, Type: 2, RepeatCount: 1, User: &User{ID: 1}, eventMeta: history})
+			live.emit(GiftEvent{ID: 2, Diamonds: 20, Type: 2, RepeatCount: 1, User: &User{ID: 2}})
+			live.endGiftStreaks()
+
+			want := 50
+			if excludeHistory {
+				want = 20
+			}
+			assert.Equal(t, want, live.Stats().Diamonds, "streaks %t, exclude history %t", streaks, excludeHistory)
+		}
+	}
+}
+
+func TestStatsAnonymousUsers(t *testing.T) {
+	live := newTestLive(t, nil)
+
+	// toUser(nil) returns an empty user, which must not collapse into one chatter or gifter.
+	live.emit(ChatEvent{Comment: "a", User: toUser(nil)})
+	live.emit(ChatEvent{Comment: "b", User: &User{Nickname: "no id"}})
+	live.emit(ChatEvent{Comment: "c"})
+	live.emit(ChatEvent{Comment: "d", User: &User{SecUID: "MS4w"}})
+	live.emit(GiftEvent{Diamonds: 5, Type: 2, User: toUser(nil)})
+	live.emit(GiftEvent{Diamonds: 7, Type: 2, User: &User{Nickname: "no id"}})
+	live.emit(GiftEvent{Diamonds: 1, Type: 2, User: &User{Username: "carol"}})
+
+	stats := live.Stats()
+	assert.Equal(t, 4, stats.Chats)
+	assert.Equal(t, 1, stats.Chatters)
+	assert.Equal(t, 13, stats.Diamonds)
+	assert.Equal(t, 3, stats.Gifts)
+	assert.Equal(t, 1, stats.Gifters)
+	if assert.Len(t, stats.TopGifters, 1) {
+		assert.Equal(t, "carol", stats.TopGifters[0].Username)
+	}
+}
+
+func TestStatsChests(t *testing.T) {
+	live := newTestLive(t, nil)
+
+	chest := EnvelopeEvent{EnvelopeID: "chest-1", Display: pb.EnvelopeDisplay_EnvelopeDisplayNew, Diamonds: 500}
+	live.emit(chest)
+	// The same chest is announced again when it is hidden.
+	chest.Display = pb.EnvelopeDisplay_EnvelopeDisplayHide
+	live.emit(chest)
+	live.emit(EnvelopeEvent{EnvelopeID: "chest-2", Display: pb.EnvelopeDisplay_EnvelopeDisplayNew, Diamonds: 100})
+	// Chests without an ID cannot be told apart, each is counted.
+	live.emit(EnvelopeEvent{Display: pb.EnvelopeDisplay_EnvelopeDisplayNew, Diamonds: 10})
+	live.emit(EnvelopeEvent{Display: pb.EnvelopeDisplay_EnvelopeDisplayNew, Diamonds: 10})
+	live.emit(SubscribeEvent{Kind: SubscribeNew})
+
+	stats := live.Stats()
+	assert.Equal(t, 4, stats.Chests)
+	assert.Equal(t, 620, stats.ChestDiamonds)
+	assert.Equal(t, 1, stats.Subscribes)
+}
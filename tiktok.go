@@ -7,7 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"go.uber.org/ratelimit"
+	"golang.org/x/time/rate"
 	"io"
 	"log/slog"
 	"maps"
@@ -25,15 +25,23 @@ import (
 
 const (
 	defaultSignerURL = "https://tiktok.eulerstream.com"
+
+	defaultReconnectMinBackoff  = 1 * time.Second
+	defaultReconnectMaxBackoff  = 30 * time.Second
+	defaultReconnectMaxAttempts = 10
+	defaultGiftCatalogTTL       = 1 * time.Hour
 )
 
 // TikTok allows you to track and discover current live streams.
 type TikTok struct {
-	c    *http.Client
-	wg   *sync.WaitGroup
-	done func() <-chan struct{}
+	c         *http.Client
+	wg        *sync.WaitGroup
+	done      func() <-chan struct{}
+	cancel    context.CancelFunc
+	closeOnce sync.Once
 
 	streams int
+	lives   map[*Live]struct{}
 	mu      *sync.Mutex
 
 	// Pass extra debug messages to debugHandler
@@ -51,51 +59,54 @@ type TikTok struct {
 	apiKey                   string
 	clientName               string
 	shouldReconnect          bool
+	reconnectMinBackoff      time.Duration
+	reconnectMaxBackoff      time.Duration
+	reconnectMaxAttempts     int
+	eventsChanSize           int
+	backpressure             BackpressurePolicy
+	spillDir                 string
+	giftStreakTimeout        time.Duration
+	statsExcludeHistory      bool
+	chatBufferSize           int
+	replaySpeed              float64
 	enableExperimentalEvents bool
 	enableExtraDebug         bool
 	enableWSTrace            bool
+	enableInterruptHandler   bool
 	wsTraceFile              string
 	wsTraceChan              chan struct{ direction, hex string }
 	wsTraceOut               *bufio.Writer
 	signerUrl                string
+	baseUrl                  string
+	apiUrl                   string
 	getLimits                bool
-	limiter                  ratelimit.Limiter
+	limiter                  *rate.Limiter
+	giftCatalogTTL           time.Duration
+	giftCatalogs             map[giftCatalogKey]*giftCatalog
 }
 
 // NewTikTok creates a tiktok instance that allows you to track live streams and
 //
 //	discover current livestreams.
 func NewTikTok(options ...TikTokLiveOption) (*TikTok, error) {
-	return NewTikTokWithApiKey(clientNameDefault, apiKeyDefault, options...)
+	return NewTikTokWithApiKeyContext(context.Background(), clientNameDefault, apiKeyDefault, options...)
+}
+
+// NewTikTokContext is like NewTikTok but the requests made during setup are bound to ctx. The ctx does not control the
+// lifetime of the returned instance.
+func NewTikTokContext(ctx context.Context, options ...TikTokLiveOption) (*TikTok, error) {
+	return NewTikTokWithApiKeyContext(ctx, clientNameDefault, apiKeyDefault, options...)
 }
 
 // NewTikTokWithApiKey allows to use an ApiKey with the default signer.
 func NewTikTokWithApiKey(clientName, apiKey string, options ...TikTokLiveOption) (*TikTok, error) {
-	jar, _ := cookiejar.New(nil)
-	wg := sync.WaitGroup{}
-	ctx, cancel := context.WithCancel(context.Background())
+	return NewTikTokWithApiKeyContext(context.Background(), clientName, apiKey, options...)
+}
 
-	tiktok := TikTok{
-		c: &http.Client{
-			Jar: jar,
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-			// Transport: &loggingTransport{},
-		},
-		wg:              &wg,
-		done:            ctx.Done,
-		mu:              &sync.Mutex{},
-		infoHandler:     defaultLogHandler,
-		warnHandler:     defaultLogHandler,
-		debugHandler:    routineErrHandler,
-		errHandler:      routineErrHandler,
-		signerUrl:       defaultSignerURL,
-		clientName:      clientName,
-		apiKey:          apiKey,
-		shouldReconnect: true,
-		getLimits:       true,
-	}
+// NewTikTokWithApiKeyContext is like NewTikTokWithApiKey but the requests made during setup are bound to setupCtx. The
+// setupCtx does not control the lifetime of the returned instance.
+func NewTikTokWithApiKeyContext(setupCtx context.Context, clientName, apiKey string, options ...TikTokLiveOption) (*TikTok, error) {
+	tiktok := newTikTok(clientName, apiKey)
 	envs := []string{"HTTP_PROXY", "HTTPS_PROXY"}
 	var optionsErr []error
 
@@ -105,26 +116,24 @@ func NewTikTokWithApiKey(clientName, apiKey string, options ...TikTokLiveOption)
 		}
 	}
 	for _, option := range options {
-		optionsErr = append(optionsErr, option(&tiktok))
+		optionsErr = append(optionsErr, option(tiktok))
 	}
 	err := errors.Join(optionsErr...)
 	if err != nil {
-		cancel()
+		tiktok.cancel()
 		return nil, err
 	}
 	if tiktok.getLimits {
-		limits, err := GetSignerLimits(tiktok.signerUrl, tiktok.apiKey)
+		limits, err := GetSignerLimitsContext(setupCtx, tiktok.signerUrl, tiktok.apiKey)
 		if err != nil {
-			cancel()
+			tiktok.cancel()
 			return nil, fmt.Errorf("cannot get signing limits: %w", err)
 		}
 		slog.Debug("limits found, using per minute limit", "day", limits.Day, "hour", limits.Hour, "minute", limits.Minute)
-		limiter := ratelimit.New(limits.Minute.Max, ratelimit.Per(1*time.Minute), ratelimit.WithoutSlack)
-		tiktok.limiter = limiter
+		tiktok.limiter = newSignLimiter(limits.Minute.Max)
 	} else {
 		slog.Debug("Request limits set to sane default of 10 per minute, for more enable GetLimits option to use signer specified limits")
-		limiter := ratelimit.New(10, ratelimit.Per(1*time.Minute), ratelimit.WithoutSlack)
-		tiktok.limiter = limiter
+		tiktok.limiter = newSignLimiter(10)
 	}
 
 	if tiktok.enableWSTrace {
@@ -138,52 +147,146 @@ func NewTikTokWithApiKey(clientName, apiKey string, options ...TikTokLiveOption)
 		f, err := os.Create(tiktok.wsTraceFile)
 		tiktok.wsTraceOut = bufio.NewWriter(f)
 
-		wg.Add(1)
+		tiktok.wg.Add(1)
 		go func() {
 			defer func() {
 				_ = f.Close()
 			}()
-			defer wg.Done()
+			defer tiktok.wg.Done()
 			for {
 				select {
-				case <-ctx.Done():
-					return
+				case <-tiktok.done():
+					// Write out whatever is still queued before closing the file.
+					for {
+						select {
+						case t := <-tiktok.wsTraceChan:
+							tiktok.writeTrace(t.direction, t.hex)
+						default:
+							tiktok.wsTraceOut.Flush()
+							return
+						}
+					}
 				case t := <-tiktok.wsTraceChan:
-					timestamp := time.Now().UTC().Format("2006-01-02 15:04:05.000")
-					tiktok.wsTraceOut.Write([]byte(timestamp))
-					tiktok.wsTraceOut.Write([]byte(t.direction))
-					tiktok.wsTraceOut.Write([]byte(" "))
-					tiktok.wsTraceOut.Write([]byte(t.hex))
-					tiktok.wsTraceOut.Write([]byte("\n"))
+					tiktok.writeTrace(t.direction, t.hex)
 					tiktok.wsTraceOut.Flush()
 				}
 			}
 		}()
 	}
 continueSetup:
-	setupInterruptHandler(
-		func(c chan os.Signal) {
-			<-c
-			cancel()
-			wg.Wait()
-
-			tiktok.infoHandler("Shutting down...")
-			os.Exit(0)
-		})
+	if tiktok.enableInterruptHandler {
+		setupInterruptHandler(
+			func(c chan os.Signal) {
+				<-c
+				_ = tiktok.Close(context.Background())
+
+				tiktok.infoHandler("Shutting down...")
+				os.Exit(0)
+			})
+	}
 
-	tiktok.sendRequest(&reqOptions{
+	tiktok.sendRequest(setupCtx, &reqOptions{
 		OmitAPI: true,
 	}, nil)
 
-	return &tiktok, nil
+	return tiktok, nil
+}
+
+// newTikTok creates an instance with the default settings without making any requests.
+func newTikTok(clientName, apiKey string) *TikTok {
+	jar, _ := cookiejar.New(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &TikTok{
+		c: &http.Client{
+			Jar: jar,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+			// Transport: &loggingTransport{},
+		},
+		wg:              &sync.WaitGroup{},
+		done:            ctx.Done,
+		cancel:          cancel,
+		lives:           make(map[*Live]struct{}),
+		mu:              &sync.Mutex{},
+		infoHandler:     defaultLogHandler,
+		warnHandler:     defaultLogHandler,
+		debugHandler:    routineErrHandler,
+		errHandler:      routineErrHandler,
+		signerUrl:       defaultSignerURL,
+		baseUrl:         tiktokBaseUrl,
+		apiUrl:          tiktokAPIUrl,
+		clientName:      clientName,
+		apiKey:          apiKey,
+		shouldReconnect: true,
+		getLimits:       true,
+
+		reconnectMinBackoff:  defaultReconnectMinBackoff,
+		reconnectMaxBackoff:  defaultReconnectMaxBackoff,
+		reconnectMaxAttempts: defaultReconnectMaxAttempts,
+		eventsChanSize:       DEFAULT_EVENTS_CHAN_SIZE,
+		backpressure:         DropOldest,
+		spillDir:             os.TempDir(),
+		replaySpeed:          1,
+		giftCatalogTTL:       defaultGiftCatalogTTL,
+		giftCatalogs:         make(map[giftCatalogKey]*giftCatalog),
+	}
+}
+
+// Close stops tracking all lives created by this instance, flushes the websocket trace file and waits for all
+// goroutines to finish. If ctx is done before everything has shut down its error is returned. After Close, no new
+// lives can be tracked.
+func (t *TikTok) Close(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		t.closeOnce.Do(func() {
+			t.mu.Lock()
+			lives := make([]*Live, 0, len(t.lives))
+			for l := range t.lives {
+				lives = append(lives, l)
+			}
+			t.mu.Unlock()
+
+			// Lives are closed before canceling the global context so that nothing is left writing to the
+			// trace channel once the trace writer drains it.
+			for _, l := range lives {
+				l.Close()
+			}
+			t.cancel()
+		})
+		t.wg.Wait()
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *TikTok) writeTrace(direction, hex string) {
+	timestamp := time.Now().UTC().Format("2006-01-02 15:04:05.000")
+	t.wsTraceOut.Write([]byte(timestamp))
+	t.wsTraceOut.Write([]byte(direction))
+	t.wsTraceOut.Write([]byte(" "))
+	t.wsTraceOut.Write([]byte(hex))
+	t.wsTraceOut.Write([]byte("\n"))
 }
 
 // GetLiveRoomUserInfo will fetch information about the user's live room which contains
 // information about the user and also the live room which contains their user ID, as well
 // as the RoomID, with which you can tell if they are live.
 func (t *TikTok) GetLiveRoomUserInfo(user string) (LiveRoomUserInfo, error) {
+	return t.GetLiveRoomUserInfoContext(context.Background(), user)
+}
+
+// GetLiveRoomUserInfoContext is like GetLiveRoomUserInfo but the request is bound to ctx.
+func (t *TikTok) GetLiveRoomUserInfoContext(ctx context.Context, user string) (LiveRoomUserInfo, error) {
 	user = cleanupUser(user)
-	body, _, err := t.sendRequest(&reqOptions{
+	body, _, err := t.sendRequest(ctx, &reqOptions{
 		Endpoint: fmt.Sprintf(urlUser+urlLive, user),
 		Query:    defaultRequestHeeaders,
 		OmitAPI:  true,
@@ -232,7 +335,12 @@ func cleanupUser(user string) string {
 //
 //	their user ID, as well as the RoomID, with which you can tell if they are live.
 func (t *TikTok) GetUserInfo(user string) (LiveRoomUser, error) {
-	roomUserInfo, err := t.GetLiveRoomUserInfo(user)
+	return t.GetUserInfoContext(context.Background(), user)
+}
+
+// GetUserInfoContext is like GetUserInfo but the request is bound to ctx.
+func (t *TikTok) GetUserInfoContext(ctx context.Context, user string) (LiveRoomUser, error) {
+	roomUserInfo, err := t.GetLiveRoomUserInfoContext(ctx, user)
 	if err != nil {
 		return LiveRoomUser{}, err
 	}
@@ -247,7 +355,12 @@ func (t *TikTok) GetUserInfo(user string) (LiveRoomUser, error) {
 //
 //	different country.
 func (t *TikTok) GetPriceList() (*PriceList, error) {
-	body, _, err := t.sendRequest(&reqOptions{
+	return t.GetPriceListContext(context.Background())
+}
+
+// GetPriceListContext is like GetPriceList but the request is bound to ctx.
+func (t *TikTok) GetPriceListContext(ctx context.Context) (*PriceList, error) {
+	body, _, err := t.sendRequest(ctx, &reqOptions{
 		Endpoint: urlPriceList,
 		Query:    defaultGETParams,
 	}, nil)
@@ -317,6 +430,11 @@ func (t *TikTok) setProxy(url string, insecure bool) error {
 // user is not found that means there was never a live by that user in the first
 // place.
 func (t *TikTok) IsLive(info LiveRoomUserInfo) (bool, error) {
+	return t.IsLiveContext(context.Background(), info)
+}
+
+// IsLiveContext is like IsLive but the request is bound to ctx.
+func (t *TikTok) IsLiveContext(ctx context.Context, info LiveRoomUserInfo) (bool, error) {
 	minGetParams := maps.Clone(minGetParams)
 	minGetParams["room_ids"] = info.LiveRoomUser.RoomID
 
@@ -336,7 +454,7 @@ func (t *TikTok) IsLive(info LiveRoomUserInfo) (bool, error) {
 		StatusCode int        `json:"status_code"`
 	}
 
-	body, _, err := t.sendRequest(&reqOptions{
+	body, _, err := t.sendRequest(ctx, &reqOptions{
 		Endpoint: urlCheckLive,
 		Query:    minGetParams,
 		OmitAPI:  false,
@@ -358,7 +476,7 @@ func (t *TikTok) IsLive(info LiveRoomUserInfo) (bool, error) {
 }
 
 func setupInterruptHandler(f func(chan os.Signal)) {
-	c := make(chan os.Signal)
+	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	go f(c)
 }
@@ -371,7 +489,16 @@ func setupInterruptHandler(f func(chan os.Signal)) {
 //	limits, _ := GetSignerLimits("https://tiktok.eulerstream.com", "MyApiKey")
 //	fmt.Printf("limits Day: %d, Hour: %d, Minutes %d\n", limits.Day, limits.Hour, limits.Minute)
 func GetSignerLimits(signer string, apiKey string) (SigningLimits, error) {
-	resp, err := http.Get(fmt.Sprintf("%s/webcast/rate_limits?apiKey=%s", signer, apiKey))
+	return GetSignerLimitsContext(context.Background(), signer, apiKey)
+}
+
+// GetSignerLimitsContext is like GetSignerLimits but the request is bound to ctx.
+func GetSignerLimitsContext(ctx context.Context, signer string, apiKey string) (SigningLimits, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/webcast/rate_limits?apiKey=%s", signer, apiKey), nil)
+	if err != nil {
+		return SigningLimits{}, fmt.Errorf("cannot create rate_limits request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return SigningLimits{}, fmt.Errorf("cannot get rate_limts: %s", err)
 	}
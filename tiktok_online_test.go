@@ -3,6 +3,7 @@
 package gotiktoklive
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"testing"
 
@@ -28,7 +29,7 @@ func TestRoomID(t *testing.T) {
 			if !assert.NoError(tt, err) {
 				return
 			}
-			id, err := tiktok.getRoomID(test.username)
+			id, err := tiktok.getRoomID(context.Background(), test.username)
 			if !assert.NoError(tt, err) {
 				return
 			}
@@ -56,7 +57,7 @@ func TestRoomInfo(t *testing.T) {
 			if !assert.NoError(tt, err) {
 				return
 			}
-			id, err := tiktok.getRoomID(test.username)
+			id, err := tiktok.getRoomID(context.Background(), test.username)
 			if !assert.NoError(tt, err) {
 				return
 			}
@@ -64,7 +65,7 @@ func TestRoomInfo(t *testing.T) {
 				t:  tiktok,
 				ID: id,
 			}
-			info, err := live.getRoomInfo()
+			info, err := live.getRoomInfo(context.Background())
 			if !assert.NoError(tt, err) {
 				return
 			}
@@ -92,7 +93,7 @@ func TestGiftInfo(t *testing.T) {
 			if !assert.NoError(tt, err) {
 				return
 			}
-			id, err := tiktok.getRoomID(test.username)
+			id, err := tiktok.getRoomID(context.Background(), test.username)
 			if !assert.NoError(tt, err) {
 				return
 			}
@@ -102,7 +103,7 @@ func TestGiftInfo(t *testing.T) {
 				ID: id,
 			}
 
-			info, err := live.getGiftInfo()
+			info, err := tiktok.getGiftInfo(context.Background(), live.ID)
 			if !assert.NoError(tt, err) {
 				return
 			}
@@ -130,7 +131,7 @@ func TestRoomData(t *testing.T) {
 			if !assert.NoError(tt, err) {
 				return
 			}
-			id, err := tiktok.getRoomID(test.username)
+			id, err := tiktok.getRoomID(context.Background(), test.username)
 			if !assert.NoError(tt, err) {
 				return
 			}
@@ -141,7 +142,7 @@ func TestRoomData(t *testing.T) {
 				Events: make(chan Event, 100),
 			}
 
-			err = live.getRoomData()
+			err = live.getRoomData(context.Background())
 			if !assert.NoError(tt, err) {
 				return
 			}
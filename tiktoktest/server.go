@@ -0,0 +1,624 @@
+// Package tiktoktest provides a fake TikTok, signer and websocket push server to test code using gotiktoklive without
+// network access.
+//
+// A test adds the rooms it needs, creates a TikTok instance with Server.Options and pushes the messages it wants the
+// tracked live to receive:
+//
+//	srv := tiktoktest.NewServer()
+//	defer srv.Close()
+//	room := srv.AddRoom("someone", "7000000000000000000")
+//
+//	tiktok, _ := gotiktoklive.NewTikTok(srv.Options()...)
+//	live, _ := tiktok.TrackUser("someone")
+//	room.Push(&pb.WebcastChatMessage{Common: &pb.Common{MsgId: 1}, Content: "hello"})
+package tiktoktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"github.com/steampoweredtaco/gotiktoklive"
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	pushPath       = "/webcast/im/push/v2/"
+	defaultQueue   = 1000
+	defaultPerMin  = 6000
+	defaultCreated = 1700000000
+)
+
+// Server is a fake TikTok website, webcast API, signer and websocket push server listening on a local port.
+type Server struct {
+	*httptest.Server
+
+	// Limits is returned by the signer rate limits endpoint. Change it before creating a TikTok instance.
+	Limits gotiktoklive.SigningLimits
+
+	mu           sync.Mutex
+	users        map[string]*Room
+	rooms        map[string]*Room
+	signRequests int
+}
+
+// Room is a live room served by a Server.
+type Room struct {
+	ID       string
+	Username string
+	Nickname string
+
+	frames chan *pb.WebcastPushFrame
+
+	mu         sync.Mutex
+	alive      bool
+	conns      map[*pushConn]struct{}
+	connected  chan struct{}
+	connects   int
+	acks       int
+	cursor     int
+	fetchMsgs  []proto.Message
+	fetchFails int
+	fetches    []string
+	gifts      []gotiktoklive.Gift
+	giftLists  int
+	segments   [][]byte
+	segFails   map[int]int
+}
+
+type pushConn struct {
+	conn   interface{ Close() error }
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (c *pushConn) close() {
+	c.once.Do(func() {
+		close(c.closed)
+		_ = c.conn.Close()
+	})
+}
+
+// NewServer starts a fake server. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		users: make(map[string]*Room),
+		rooms: make(map[string]*Room),
+		Limits: gotiktoklive.SigningLimits{
+			Day:    gotiktoklive.LimitInfo{Max: 24 * 60 * defaultPerMin, Remaining: 24 * 60 * defaultPerMin},
+			Hour:   gotiktoklive.LimitInfo{Max: 60 * defaultPerMin, Remaining: 60 * defaultPerMin},
+			Minute: gotiktoklive.LimitInfo{Max: defaultPerMin, Remaining: defaultPerMin},
+		},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/{user}/live", s.serveUser)
+	mux.HandleFunc("/{user}/live/", s.serveUser)
+	mux.HandleFunc("/webcast/room/info/", s.serveRoomInfo)
+	mux.HandleFunc("/webcast/room/check_alive/", s.serveCheckAlive)
+	mux.HandleFunc("/webcast/gift/list/", s.serveGiftList)
+	mux.HandleFunc("/stream/pull/{file}", s.serveStream)
+	mux.HandleFunc("/stream/pull/{room}/{segment}", s.serveSegment)
+	mux.HandleFunc("/webcast/rate_limits", s.serveRateLimits)
+	mux.HandleFunc("/webcast/fetch/", s.serveFetch)
+	mux.HandleFunc(pushPath, s.servePush)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Close disconnects all websocket clients and shuts down the server.
+func (s *Server) Close() {
+	s.mu.Lock()
+	rooms := make([]*Room, 0, len(s.rooms))
+	for _, r := range s.rooms {
+		rooms = append(rooms, r)
+	}
+	s.mu.Unlock()
+	for _, r := range rooms {
+		r.Disconnect()
+	}
+	s.Server.Close()
+}
+
+// Options returns the options that point a TikTok instance at the server.
+func (s *Server) Options() []gotiktoklive.TikTokLiveOption {
+	return []gotiktoklive.TikTokLiveOption{
+		gotiktoklive.BaseUrl(s.URL + "/"),
+		gotiktoklive.ApiUrl(s.URL + "/webcast/"),
+		gotiktoklive.SigningUrl(s.URL),
+	}
+}
+
+// AddRoom adds a room that is live and hosted by username.
+func (s *Server) AddRoom(username, roomID string) *Room {
+	r := &Room{
+		ID:        roomID,
+		Username:  username,
+		Nickname:  username,
+		frames:    make(chan *pb.WebcastPushFrame, defaultQueue),
+		alive:     true,
+		conns:     make(map[*pushConn]struct{}),
+		connected: make(chan struct{}),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[username] = r
+	s.rooms[roomID] = r
+	return r
+}
+
+// Room returns the room with the given ID, or nil.
+func (s *Server) Room(roomID string) *Room {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rooms[roomID]
+}
+
+// SignRequests returns how many requests the signer received.
+func (s *Server) SignRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.signRequests
+}
+
+// SetGifts sets the gift catalog of the room.
+func (r *Room) SetGifts(gifts ...gotiktoklive.Gift) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gifts = gifts
+}
+
+// SetStream sets the video stream of the room. The FLV stream is all segments at once, the HLS playlist lists every
+// segment and is ended.
+func (r *Room) SetStream(segments ...[]byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.segments = segments
+}
+
+// FailSegment makes the next n requests for the HLS segment with index i fail.
+func (r *Room) FailSegment(i, n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.segFails == nil {
+		r.segFails = make(map[int]int)
+	}
+	r.segFails[i] = n
+}
+
+// SetAlive sets whether the room is live. A room that is not alive is reported as ended by the room info endpoint.
+func (r *Room) SetAlive(alive bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alive = alive
+}
+
+// FetchMessages sets the messages returned with the room data fetched through the signer. Like TikTok, the messages
+// are returned again on every fetch, also when resuming after a reconnect.
+func (r *Room) FetchMessages(msgs ...proto.Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fetchMsgs = msgs
+}
+
+// FailFetches makes the next n room data fetches fail, as if TikTok or the signer were unavailable.
+func (r *Room) FailFetches(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fetchFails = n
+}
+
+// FetchCursors returns the cursor every room data fetch resumed from, empty for a fetch without cursor.
+func (r *Room) FetchCursors() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.fetches...)
+}
+
+// Push queues a frame with the messages for the websocket client of the room. Frames are queued until a client is
+// connected and each frame is delivered to one client only.
+func (r *Room) Push(msgs ...proto.Message) error {
+	frame, err := MessageFrame(msgs...)
+	if err != nil {
+		return err
+	}
+	r.PushFrame(frame)
+	return nil
+}
+
+// PushFrame queues a raw frame for the websocket client of the room.
+func (r *Room) PushFrame(frame *pb.WebcastPushFrame) {
+	r.frames <- frame
+}
+
+// Connected returns a channel that is closed once a websocket client connected to the room.
+func (r *Room) Connected() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.connected
+}
+
+// Connects returns how many times a websocket client connected to the room.
+func (r *Room) Connects() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.connects
+}
+
+// GiftFetches returns how many times the gift catalog of the room was fetched.
+func (r *Room) GiftFetches() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.giftLists
+}
+
+// Acks returns how many ack frames the websocket clients of the room sent.
+func (r *Room) Acks() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.acks
+}
+
+// Disconnect closes all websocket connections of the room, as if the connection dropped.
+func (r *Room) Disconnect() {
+	r.mu.Lock()
+	conns := make([]*pushConn, 0, len(r.conns))
+	for c := range r.conns {
+		conns = append(conns, c)
+	}
+	r.mu.Unlock()
+	for _, c := range conns {
+		c.close()
+	}
+}
+
+// MessageFrame wraps messages in a WebcastResponse inside a WebcastPushFrame as sent by the push server. The method of
+// each message is its proto message name.
+func MessageFrame(msgs ...proto.Message) (*pb.WebcastPushFrame, error) {
+	response, err := webcastResponse(msgs)
+	if err != nil {
+		return nil, err
+	}
+	response.NeedsAck = true
+	payload, err := proto.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.WebcastPushFrame{PayloadType: "msg", Payload: payload}, nil
+}
+
+func webcastResponse(msgs []proto.Message) (*pb.WebcastResponse, error) {
+	response := &pb.WebcastResponse{}
+	for _, msg := range msgs {
+		payload, err := proto.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		response.Messages = append(response.Messages, &pb.WebcastResponse_Message{
+			Method:  string(proto.MessageName(msg)),
+			Payload: payload,
+		})
+	}
+	return response, nil
+}
+
+func (s *Server) serveUser(w http.ResponseWriter, r *http.Request) {
+	username := strings.TrimPrefix(r.PathValue("user"), "@")
+	s.mu.Lock()
+	room, ok := s.users[username]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	room.mu.Lock()
+	roomID := room.ID
+	if !room.alive {
+		roomID = ""
+	}
+	room.mu.Unlock()
+
+	state := map[string]interface{}{
+		"LiveRoom": map[string]interface{}{
+			"liveRoomUserInfo": map[string]interface{}{
+				"user": map[string]interface{}{
+					"id":       room.ID,
+					"uniqueId": room.Username,
+					"nickname": room.Nickname,
+					"roomId":   roomID,
+					"status":   2,
+				},
+				"liveRoom": map[string]interface{}{
+					"title":     room.Nickname + "'s live",
+					"startTime": defaultCreated,
+					"status":    2,
+				},
+			},
+		},
+	}
+	b, err := json.Marshal(state)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	fmt.Fprintf(w, `<html><head><title>%s</title></head><body><script id="SIGI_STATE" type="application/json">%s</script></body></html>`,
+		html.EscapeString(room.Nickname), b)
+}
+
+func (s *Server) serveRoomInfo(w http.ResponseWriter, r *http.Request) {
+	room := s.Room(r.URL.Query().Get("room_id"))
+	if room == nil {
+		writeJSON(w, map[string]interface{}{"data": map[string]interface{}{"status": 4}, "status_code": 0})
+		return
+	}
+	room.mu.Lock()
+	status := 2
+	if !room.alive {
+		status = 4
+	}
+	room.mu.Unlock()
+
+	writeJSON(w, map[string]interface{}{
+		"data": map[string]interface{}{
+			"id_str":      room.ID,
+			"status":      status,
+			"title":       room.Nickname + "'s live",
+			"create_time": defaultCreated,
+			"owner": map[string]interface{}{
+				"id_str":     room.ID,
+				"display_id": room.Username,
+				"nickname":   room.Nickname,
+			},
+			"stream_url": map[string]interface{}{
+				"hls_pull_url": s.URL + "/stream/pull/" + room.ID + ".m3u8",
+				"flv_pull_url": map[string]interface{}{
+					"FULL_HD1": s.URL + "/stream/pull/" + room.ID + ".flv",
+				},
+			},
+		},
+		"extra":       map[string]interface{}{"now": time.Now().UnixMilli()},
+		"status_code": 0,
+	})
+}
+
+func (s *Server) serveGiftList(w http.ResponseWriter, r *http.Request) {
+	gifts := []map[string]interface{}{}
+	if room := s.Room(r.URL.Query().Get("room_id")); room != nil {
+		room.mu.Lock()
+		room.giftLists++
+		for _, g := range room.gifts {
+			gift := map[string]interface{}{
+				"id":            g.ID,
+				"name":          g.Name,
+				"describe":      g.Describe,
+				"diamond_count": g.Diamonds,
+				"combo":         g.Combo,
+				"type":          g.Type,
+				"region":        g.Region,
+			}
+			if g.Icon != nil {
+				gift["icon"] = map[string]interface{}{"url_list": g.Icon.Urls}
+			}
+			if g.Image != nil {
+				gift["image"] = map[string]interface{}{"url_list": g.Image.Urls}
+			}
+			gifts = append(gifts, gift)
+		}
+		room.mu.Unlock()
+	}
+	writeJSON(w, map[string]interface{}{
+		"data":        map[string]interface{}{"gifts": gifts},
+		"extra":       map[string]interface{}{"now": time.Now().UnixMilli()},
+		"status_code": 0,
+	})
+}
+
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request) {
+	file := r.PathValue("file")
+	id, ext, _ := strings.Cut(file, ".")
+	room := s.Room(id)
+	if room == nil {
+		http.NotFound(w, r)
+		return
+	}
+	room.mu.Lock()
+	segments := room.segments
+	room.mu.Unlock()
+
+	switch ext {
+	case "flv":
+		w.Header().Set("Content-Type", "video/x-flv")
+		for _, segment := range segments {
+			_, _ = w.Write(segment)
+		}
+	case "m3u8":
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		fmt.Fprint(w, "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:1\n#EXT-X-MEDIA-SEQUENCE:0\n")
+		for i := range segments {
+			fmt.Fprintf(w, "#EXTINF:1.0,\n%s/%d.ts\n", room.ID, i)
+		}
+		fmt.Fprint(w, "#EXT-X-ENDLIST\n")
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveSegment(w http.ResponseWriter, r *http.Request) {
+	room := s.Room(r.PathValue("room"))
+	n, _, _ := strings.Cut(r.PathValue("segment"), ".")
+	if room == nil {
+		http.NotFound(w, r)
+		return
+	}
+	room.mu.Lock()
+	defer room.mu.Unlock()
+	i, err := strconv.Atoi(n)
+	if err != nil || i < 0 || i >= len(room.segments) {
+		http.NotFound(w, r)
+		return
+	}
+	if room.segFails[i] > 0 {
+		room.segFails[i]--
+		http.Error(w, "segment unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "video/mp2t")
+	_, _ = w.Write(room.segments[i])
+}
+
+func (s *Server) serveCheckAlive(w http.ResponseWriter, r *http.Request) {
+	type item struct {
+		Alive     bool   `json:"alive"`
+		RoomIDStr string `json:"room_id_str"`
+	}
+	var data []item
+	for _, id := range strings.Split(r.URL.Query().Get("room_ids"), ",") {
+		alive := false
+		if room := s.Room(id); room != nil {
+			room.mu.Lock()
+			alive = room.alive
+			room.mu.Unlock()
+		}
+		data = append(data, item{Alive: alive, RoomIDStr: id})
+	}
+	writeJSON(w, map[string]interface{}{"data": data, "status_code": 0})
+}
+
+func (s *Server) serveRateLimits(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	limits := s.Limits
+	s.mu.Unlock()
+	writeJSON(w, limits)
+}
+
+// serveFetch acts as the signer, which fetches the room data from TikTok and returns the WebcastResponse.
+func (s *Server) serveFetch(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.signRequests++
+	s.mu.Unlock()
+
+	room := s.Room(r.URL.Query().Get("room_id"))
+	if room == nil {
+		http.Error(w, "unknown room", http.StatusNotFound)
+		return
+	}
+
+	room.mu.Lock()
+	if room.fetchFails > 0 {
+		room.fetchFails--
+		room.mu.Unlock()
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var resumed string
+	if u, err := url.Parse(r.URL.Query().Get("url")); err == nil {
+		resumed = u.Query().Get("cursor")
+	}
+	room.fetches = append(room.fetches, resumed)
+	msgs := room.fetchMsgs
+	room.cursor++
+	cursor := room.cursor
+	room.mu.Unlock()
+
+	response, err := webcastResponse(msgs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response.Cursor = fmt.Sprintf("cursor-%d", cursor)
+	response.InternalExt = []byte(fmt.Sprintf("internal_ext-%d", cursor))
+	response.FetchInterval = 1000
+	response.Now = time.Now().UnixMilli()
+	response.PushServer = "ws" + strings.TrimPrefix(s.URL, "http") + pushPath
+	response.RouteParamsMap = map[string]string{"room_id": room.ID}
+
+	b, err := proto.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/protobuf")
+	w.Header().Set("X-Set-TT-Cookie", "ttwid=tiktoktest")
+	_, _ = w.Write(b)
+}
+
+func (s *Server) servePush(w http.ResponseWriter, r *http.Request) {
+	room := s.Room(r.URL.Query().Get("room_id"))
+	if room == nil {
+		http.Error(w, "unknown room", http.StatusNotFound)
+		return
+	}
+	conn, _, _, err := ws.UpgradeHTTP(r, w)
+	if err != nil {
+		return
+	}
+	c := &pushConn{conn: conn, closed: make(chan struct{})}
+
+	room.mu.Lock()
+	room.conns[c] = struct{}{}
+	room.connects++
+	select {
+	case <-room.connected:
+	default:
+		close(room.connected)
+	}
+	room.mu.Unlock()
+
+	defer func() {
+		room.mu.Lock()
+		delete(room.conns, c)
+		room.mu.Unlock()
+		c.close()
+	}()
+
+	// Read client frames until the connection is closed, counting acks.
+	go func() {
+		defer c.close()
+		for {
+			data, err := wsutil.ReadClientBinary(conn)
+			if err != nil {
+				return
+			}
+			var frame pb.WebcastPushFrame
+			if proto.Unmarshal(data, &frame) == nil && frame.PayloadType == "ack" {
+				room.mu.Lock()
+				room.acks++
+				room.mu.Unlock()
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-c.closed:
+			return
+		case frame := <-room.frames:
+			b, err := proto.Marshal(frame)
+			if err != nil {
+				continue
+			}
+			if err := wsutil.WriteServerBinary(conn, b); err != nil {
+				// Put it back for the next client.
+				room.frames <- frame
+				return
+			}
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
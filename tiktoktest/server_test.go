@@ -0,0 +1,90 @@
+package tiktoktest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steampoweredtaco/gotiktoklive"
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTikTok(t *testing.T, srv *Server) *gotiktoklive.TikTok {
+	options := append(srv.Options(), gotiktoklive.ReconnectBackoff(10*time.Millisecond, 50*time.Millisecond))
+	tiktok, err := gotiktoklive.NewTikTok(options...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = tiktok.Close(ctx)
+	})
+	return tiktok
+}
+
+func nextEvent[T gotiktoklive.Event](t *testing.T, live *gotiktoklive.Live) T {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-live.Events:
+			if !ok {
+				t.Fatal("events closed")
+			}
+			if e, ok := e.(T); ok {
+				return e
+			}
+		case <-timeout:
+			var zero T
+			t.Fatalf("no %T received", zero)
+		}
+	}
+}
+
+func TestServerTrackUser(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	room := srv.AddRoom("tester", "7000000000000000001")
+	room.FetchMessages(&pb.WebcastChatMessage{Common: &pb.Common{MsgId: 8000000000000000001}, Content: "fetched"})
+
+	tiktok := newTikTok(t, srv)
+
+	info, err := tiktok.GetLiveRoomUserInfo("@tester")
+	require.NoError(t, err)
+	assert.Equal(t, room.ID, info.LiveRoomUser.RoomID)
+	alive, err := tiktok.IsLive(info)
+	require.NoError(t, err)
+	assert.True(t, alive)
+
+	live, err := tiktok.TrackUser("tester")
+	require.NoError(t, err)
+	assert.Equal(t, "tester", live.Info.Owner.Username)
+	assert.Equal(t, "fetched", nextEvent[gotiktoklive.ChatEvent](t, live).Comment)
+
+	require.NoError(t, room.Push(&pb.WebcastChatMessage{Common: &pb.Common{MsgId: 8000000000000000002}, Content: "pushed"}))
+	assert.Equal(t, "pushed", nextEvent[gotiktoklive.ChatEvent](t, live).Comment)
+	assert.Eventually(t, func() bool { return room.Acks() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, srv.SignRequests())
+
+	// A dropped connection is resumed.
+	room.Disconnect()
+	nextEvent[gotiktoklive.ReconnectedEvent](t, live)
+	require.NoError(t, room.Push(&pb.WebcastChatMessage{Common: &pb.Common{MsgId: 8000000000000000003}, Content: "resumed"}))
+	assert.Equal(t, "resumed", nextEvent[gotiktoklive.ChatEvent](t, live).Comment)
+	assert.Equal(t, 2, room.Connects())
+
+	// Once the room is no longer alive, the live ends instead of reconnecting.
+	room.SetAlive(false)
+	room.Disconnect()
+	nextEvent[*gotiktoklive.DisconnectEvent](t, live)
+}
+
+func TestServerUnknownUser(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	tiktok := newTikTok(t, srv)
+
+	_, err := tiktok.TrackUser("nobody")
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package gotiktoklive_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steampoweredtaco/gotiktoklive"
+	"github.com/steampoweredtaco/gotiktoklive/tiktoktest"
+	"github.com/stretchr/testify/require"
+)
+
+// newServerTikTok returns a TikTok talking to the fake server, closed when the test ends.
+func newServerTikTok(t *testing.T, srv *tiktoktest.Server, options ...gotiktoklive.TikTokLiveOption) *gotiktoklive.TikTok {
+	options = append(append(srv.Options(), gotiktoklive.ReconnectBackoff(10*time.Millisecond, 50*time.Millisecond)), options...)
+	tiktok, err := gotiktoklive.NewTikTok(options...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = tiktok.Close(ctx)
+	})
+	return tiktok
+}
+
+func nextEvent[T gotiktoklive.Event](t *testing.T, live *gotiktoklive.Live) T {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-live.Events:
+			if !ok {
+				t.Fatal("events closed")
+			}
+			if e, ok := e.(T); ok {
+				return e
+			}
+		case <-timeout:
+			var zero T
+			t.Fatalf("no %T received", zero)
+		}
+	}
+}
+
+// eventsUntil collects the events of the live up to and including the first T.
+func eventsUntil[T gotiktoklive.Event](t *testing.T, live *gotiktoklive.Live) []gotiktoklive.Event {
+	t.Helper()
+	var events []gotiktoklive.Event
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-live.Events:
+			if !ok {
+				t.Fatal("events closed")
+			}
+			events = append(events, e)
+			if _, ok := e.(T); ok {
+				return events
+			}
+		case <-timeout:
+			var zero T
+			t.Fatalf("no %T received", zero)
+		}
+	}
+}
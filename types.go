@@ -1,18 +1,27 @@
 package gotiktoklive
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	pb "github.com/steampoweredtaco/gotiktoklive/proto"
+)
 
 type Event interface {
 	CreatedTimestamp() int64
 	IsHistory() bool
 }
 
+// RoomEvent is emitted for messages of the room and pinned messages. Message is the plain text, DisplayText holds the
+// users and gifts mentioned if TikTok sent them along.
 type RoomEvent struct {
-	Timestamp int64
-	MessageID int64
-	Type      string
-	Message   string
-	isHistory bool
+	Timestamp   int64
+	MessageID   int64
+	Type        string
+	Message     string
+	DisplayText DisplayText
+	eventMeta
 }
 
 func (r RoomEvent) CreatedTimestamp() int64 {
@@ -29,7 +38,7 @@ type ChatEvent struct {
 	Comment      string
 	User         *User
 	UserIdentity *UserIdentity
-	isHistory    bool
+	eventMeta
 }
 
 func (c ChatEvent) IsHistory() bool {
@@ -49,11 +58,12 @@ const (
 )
 
 type UserEvent struct {
-	Timestamp int64
-	MessageID int64
-	Event     userEventType
-	User      *User
-	isHistory bool
+	Timestamp   int64
+	MessageID   int64
+	Event       userEventType
+	User        *User
+	DisplayText DisplayText
+	eventMeta
 }
 
 func (u UserEvent) CreatedTimestamp() int64 {
@@ -68,7 +78,7 @@ type ViewersEvent struct {
 	Timestamp int64
 	MessageID int64
 	Viewers   int
-	isHistory bool
+	eventMeta
 }
 
 func (v ViewersEvent) TimeComparableID() int64 {
@@ -90,15 +100,17 @@ type GiftEvent struct {
 	Name         string
 	Describe     string
 	Diamonds     int
+	Icon         *ProfilePicture
+	Image        *ProfilePicture
 	RepeatCount  int
 	RepeatEnd    bool
 	Type         int
 	ToUserID     int64
 	User         *User
 	UserIdentity *UserIdentity
-	isHistory    bool
-	GroupID      int64
-	IsComboGift  bool
+	eventMeta
+	GroupID     int64
+	IsComboGift bool
 }
 
 func (g GiftEvent) CreatedTimestamp() int64 {
@@ -117,7 +129,8 @@ type LikeEvent struct {
 	User        *User
 	DisplayType string
 	Label       string
-	isHistory   bool
+	DisplayText DisplayText
+	eventMeta
 }
 
 func (l LikeEvent) IsHistory() bool {
@@ -133,7 +146,7 @@ type QuestionEvent struct {
 	Timestamp int64
 	Quesion   string
 	User      *User
-	isHistory bool
+	eventMeta
 }
 
 func (q QuestionEvent) CreatedTimestamp() int64 {
@@ -149,7 +162,7 @@ type ControlEvent struct {
 	Timestamp   int64
 	Action      int
 	Description string
-	isHistory   bool
+	eventMeta
 }
 
 func (c ControlEvent) IsHistory() bool {
@@ -164,11 +177,17 @@ func (c ControlEvent) CreatedTimestamp() int64 {
 	return c.Timestamp
 }
 
+// MicBattleEvent is emitted when a link mic battle starts, ends or changes. Users holds all hosts, Teams groups them
+// into the teams of the battle with their scores and top viewers. Use OnBattleStarted, OnBattleScore and
+// OnBattleEnded to follow a battle without correlating the raw messages yourself.
 type MicBattleEvent struct {
 	MessageID int64
 	Timestamp int64
+	BattleID  int64
+	Status    pb.LinkMicBattleStatus
 	Users     []*User
-	isHistory bool
+	Teams     []BattleTeam
+	eventMeta
 }
 
 func (m MicBattleEvent) IsHistory() bool {
@@ -179,12 +198,14 @@ func (m MicBattleEvent) CreatedTimestamp() int64 {
 	return m.Timestamp
 }
 
+// BattlesEvent is emitted with the points and gifters of the hosts during a link mic battle.
 type BattlesEvent struct {
 	MessageID int64
 	Timestamp int64
+	BattleID  int64
 	Status    int
 	Battles   []*Battle
-	isHistory bool
+	eventMeta
 }
 
 func (b BattlesEvent) IsHistory() bool {
@@ -199,7 +220,7 @@ type RoomBannerEvent struct {
 	MessageID int64
 	Timestamp int64
 	Data      interface{}
-	isHistory bool
+	eventMeta
 }
 
 func (r RoomBannerEvent) IsHistory() bool {
@@ -216,7 +237,7 @@ type IntroEvent struct {
 	ID        int
 	Title     string
 	User      *User
-	isHistory bool
+	eventMeta
 }
 
 func (i IntroEvent) IsHistory() bool {
@@ -231,6 +252,562 @@ func (i IntroEvent) CreatedTimestamp() int64 {
 	return i.Timestamp
 }
 
+// GiftStreakUpdateEvent is emitted with EnableGiftStreaks every time the count of a running combo gift streak goes up.
+// Count and Diamonds are the totals of the streak so far, Gift is the latest GiftEvent of the streak. It is history if
+// Gift is.
+type GiftStreakUpdateEvent struct {
+	Gift      GiftEvent
+	Count     int
+	Diamonds  int
+	StartedAt int64
+	eventMeta
+}
+
+func (g GiftStreakUpdateEvent) CreatedTimestamp() int64 {
+	return g.Gift.Timestamp
+}
+
+func (g GiftStreakUpdateEvent) IsHistory() bool {
+	return g.isHistory
+}
+
+func (g *GiftStreakUpdateEvent) restoreSpill(state spillState) {
+	g.eventMeta.restoreSpill(state)
+	g.Gift.isHistory = state.History
+}
+
+// GiftStreakEndEvent is emitted with EnableGiftStreaks once per gift streak, when the final gift of the combo arrived
+// or TimedOut if no update was seen for the configured timeout. Gifts that cannot be comboed end their streak right
+// away. Count and Diamonds are the totals of the streak, use these to count coins without double counting. It is
+// history if the last gift of the streak is.
+type GiftStreakEndEvent struct {
+	Gift      GiftEvent
+	Count     int
+	Diamonds  int
+	StartedAt int64
+	TimedOut  bool
+	eventMeta
+}
+
+func (g GiftStreakEndEvent) CreatedTimestamp() int64 {
+	return g.Gift.Timestamp
+}
+
+func (g GiftStreakEndEvent) IsHistory() bool {
+	return g.isHistory
+}
+
+func (g *GiftStreakEndEvent) restoreSpill(state spillState) {
+	g.eventMeta.restoreSpill(state)
+	g.Gift.isHistory = state.History
+}
+
+// PollStartEvent is emitted when the host starts a poll.
+type PollStartEvent struct {
+	MessageID int64
+	Timestamp int64
+	PollID    int64
+	Title     string
+	Options   []PollOption
+	StartTime int64
+	EndTime   int64
+	Operator  *User
+	eventMeta
+}
+
+func (p PollStartEvent) CreatedTimestamp() int64 {
+	return p.Timestamp
+}
+
+func (p PollStartEvent) IsHistory() bool {
+	return p.isHistory
+}
+
+// PollUpdateEvent is emitted while a poll is running with the current vote counts.
+type PollUpdateEvent struct {
+	MessageID int64
+	Timestamp int64
+	PollID    int64
+	Options   []PollOption
+	eventMeta
+}
+
+func (p PollUpdateEvent) CreatedTimestamp() int64 {
+	return p.Timestamp
+}
+
+func (p PollUpdateEvent) IsHistory() bool {
+	return p.isHistory
+}
+
+// PollEndEvent is emitted when a poll ends with the final vote counts.
+type PollEndEvent struct {
+	MessageID int64
+	Timestamp int64
+	PollID    int64
+	Options   []PollOption
+	EndType   int
+	Operator  *User
+	eventMeta
+}
+
+func (p PollEndEvent) CreatedTimestamp() int64 {
+	return p.Timestamp
+}
+
+func (p PollEndEvent) IsHistory() bool {
+	return p.isHistory
+}
+
+// PollOption is an answer of a poll. Voters holds the voters TikTok sent along, usually only a few of them.
+type PollOption struct {
+	Index  int
+	Text   string
+	Votes  int
+	Voters []*User
+}
+
+// EnvelopeEvent is emitted when a treasure chest is dropped in the room, or hidden again once it was opened. Diamonds
+// is the value of the chest shared by up to People viewers. OpenAt is when the chest can be opened, derived from the
+// unpack time sent by TikTok, and is the zero time if unknown.
+type EnvelopeEvent struct {
+	MessageID        int64
+	Timestamp        int64
+	EnvelopeID       string
+	BusinessType     pb.EnvelopeBusinessType
+	Display          pb.EnvelopeDisplay
+	FollowShowStatus pb.EnvelopeFollowShowStatus
+	Sender           *User
+	Diamonds         int
+	People           int
+	CreatedAt        time.Time
+	OpenAt           time.Time
+	eventMeta
+}
+
+func (e EnvelopeEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e EnvelopeEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// CaptionEvent is emitted for every line of the automatic closed captions of the stream. Time is when the caption
+// was spoken, taken from the caption timestamp and falling back to the message creation time. Use a CaptionWriter or
+// Live.RecordCaptions to write them as subtitles.
+type CaptionEvent struct {
+	MessageID int64
+	Timestamp int64
+	Time      time.Time
+	Language  string
+	Text      string
+	eventMeta
+}
+
+func (e CaptionEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e CaptionEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// EmoteChatEvent is emitted when a viewer comments with subscriber emotes or stickers instead of text.
+type EmoteChatEvent struct {
+	MessageID    int64
+	Timestamp    int64
+	User         *User
+	UserIdentity *UserIdentity
+	Emotes       []Emote
+	eventMeta
+}
+
+func (e EmoteChatEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e EmoteChatEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// Emote is an emote sent in an EmoteChatEvent.
+type Emote struct {
+	ID    string
+	Image *ProfilePicture
+	Type  pb.EmoteType
+}
+
+// BarrageEvent is emitted for the banners shown over the chat, such as a high level viewer entering or a fan club
+// upgrade. Depending on Type, User and Level are set for user grade and fan level barrages and GiftSubCount for
+// gifted subscriptions.
+type BarrageEvent struct {
+	MessageID    int64
+	Timestamp    int64
+	Type         pb.WebcastBarrageMessage_BarrageType
+	EventName    string
+	Content      string
+	Icon         *ProfilePicture
+	Duration     int
+	User         *User
+	Level        int
+	GiftSubCount int
+	eventMeta
+}
+
+func (e BarrageEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e BarrageEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// RankUpdateEvent is emitted when the position of the host changes on one of the LIVE rankings, such as the hourly or
+// weekly ranking.
+type RankUpdateEvent struct {
+	MessageID int64
+	Timestamp int64
+	GroupType int64
+	Updates   []RankUpdate
+	Tabs      []RankTab
+	eventMeta
+}
+
+func (e RankUpdateEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e RankUpdateEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// RankUpdate is the position of the host on a ranking. OnRank is false if the host is not on the ranking at all.
+type RankUpdate struct {
+	RankType  int64
+	Rank      int
+	OnRank    bool
+	Text      string
+	Countdown int64
+}
+
+// RankTab is a ranking shown in the ranking list of the room.
+type RankTab struct {
+	RankType int64
+	Title    string
+}
+
+// RankTextEvent is emitted with the text shown when the host moves up on a ranking. Rank is zero if not ranked.
+type RankTextEvent struct {
+	MessageID  int64
+	Timestamp  int64
+	Scene      int
+	UserID     int64
+	RankBefore int
+	Rank       int
+	Text       string
+	SelfText   string
+	eventMeta
+}
+
+func (e RankTextEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e RankTextEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// HourlyRankEvent is emitted with the hourly ranking banner of the host.
+type HourlyRankEvent struct {
+	MessageID int64
+	Timestamp int64
+	Rankings  []Ranking
+	eventMeta
+}
+
+func (e HourlyRankEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e HourlyRankEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// Ranking is a ranking banner of an HourlyRankEvent, Label is the text shown and Details the text of its parts.
+type Ranking struct {
+	Type    string
+	Label   string
+	Details []string
+}
+
+// BattlePunishFinishEvent is emitted when the punishment phase after a link mic battle is over.
+type BattlePunishFinishEvent struct {
+	MessageID int64
+	Timestamp int64
+	BattleID  int64
+	ChannelID int64
+	eventMeta
+}
+
+func (e BattlePunishFinishEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e BattlePunishFinishEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// BattleTaskEvent is emitted for the tasks of a link mic battle, such as reaching a score in time for a bonus. The
+// meaning of Type and Value is not fully known, they are passed along as sent.
+type BattleTaskEvent struct {
+	MessageID int64
+	Timestamp int64
+	BattleID  int64
+	Type      int
+	Value     int
+	eventMeta
+}
+
+func (e BattleTaskEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e BattleTaskEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// LinkMicMethodEvent is emitted when hosts or guests join, leave or are invited to the link mic.
+type LinkMicMethodEvent struct {
+	MessageID      int64
+	Timestamp      int64
+	Type           pb.MessageType
+	UserID         int64
+	InviterID      int64
+	ChannelID      int64
+	LinkMicID      int64
+	FanTicket      int
+	TotalFanTicket int
+	eventMeta
+}
+
+func (e LinkMicMethodEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e LinkMicMethodEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// LinkMicFanTicketEvent is emitted with the scores of the hosts and guests on the link mic. During a battle MatchID
+// is the ID of the battle.
+type LinkMicFanTicketEvent struct {
+	MessageID int64
+	Timestamp int64
+	MatchID   int64
+	Total     int
+	Users     []FanTicket
+	eventMeta
+}
+
+func (e LinkMicFanTicketEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e LinkMicFanTicketEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// FanTicket is the score of a user on the link mic.
+type FanTicket struct {
+	UserID    int64
+	FanTicket int
+	Score     int
+	Rank      int
+}
+
+// BattleStartedEvent is emitted once when a link mic battle starts, see Live.Battle.
+type BattleStartedEvent struct {
+	Timestamp int64
+	Battle    BattleState
+	eventMeta
+}
+
+func (e BattleStartedEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e BattleStartedEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// BattleScoreEvent is emitted every time the scores or top viewers of the running link mic battle are updated.
+type BattleScoreEvent struct {
+	Timestamp int64
+	Battle    BattleState
+	eventMeta
+}
+
+func (e BattleScoreEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e BattleScoreEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// BattleEndedEvent is emitted once when a link mic battle is decided and the punishment phase starts. Use
+// BattleState.Winner to get the winning team.
+type BattleEndedEvent struct {
+	Timestamp int64
+	Battle    BattleState
+	eventMeta
+}
+
+func (e BattleEndedEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e BattleEndedEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// ShoppingAction is what happened to the product of a ShoppingEvent.
+type ShoppingAction int
+
+const (
+	// ShoppingPin is sent when the host pins a product to the stream.
+	ShoppingPin ShoppingAction = iota
+	// ShoppingUnpin is sent when the pinned product is removed again.
+	ShoppingUnpin
+)
+
+func (a ShoppingAction) String() string {
+	switch a {
+	case ShoppingPin:
+		return "pin"
+	case ShoppingUnpin:
+		return "unpin"
+	}
+	return fmt.Sprintf("ShoppingAction(%d)", int(a))
+}
+
+// ShoppingEvent is emitted when the host of a shopping live pins or unpins a product. Price is formatted in the
+// currency of the shop, such as "$55.99". Action is derived from whether product data was sent along, Type is the raw
+// type sent by TikTok. StartTime and EndTime are the epoch timestamps sent with the pin, zero if unknown.
+type ShoppingEvent struct {
+	MessageID int64
+	Timestamp int64
+	Action    ShoppingAction
+	Type      int
+	ShopID    string
+	ShopName  string
+	ShopURL   string
+	Title     string
+	Price     string
+	ImageURL  string
+	StartTime int64
+	EndTime   int64
+	eventMeta
+}
+
+func (e ShoppingEvent) CreatedTimestamp() int64 {
+	return e.Timestamp
+}
+
+func (e ShoppingEvent) IsHistory() bool {
+	return e.isHistory
+}
+
+// MessageDeletedEvent is emitted when moderators remove chat messages, or all messages of users. Use
+// EnableChatBuffer to have recent chat messages marked as deleted.
+type MessageDeletedEvent struct {
+	MessageID  int64
+	Timestamp  int64
+	MessageIDs []int64
+	UserIDs    []int64
+	eventMeta
+}
+
+func (m MessageDeletedEvent) CreatedTimestamp() int64 {
+	return m.Timestamp
+}
+
+func (m MessageDeletedEvent) IsHistory() bool {
+	return m.isHistory
+}
+
+// GoalUpdateEvent is emitted when a LIVE goal is set or changes, usually because a viewer contributed to it. Goal
+// holds the complete state of the goal after the update, see also Live.Goals.
+type GoalUpdateEvent struct {
+	MessageID         int64
+	Timestamp         int64
+	Goal              Goal
+	ContributorID     int64
+	ContributorName   string
+	ContributorAvatar *ProfilePicture
+	ContributeCount   int
+	ContributeScore   int
+	Pinned            bool
+	Unpinned          bool
+	eventMeta
+}
+
+func (g GoalUpdateEvent) CreatedTimestamp() int64 {
+	return g.Timestamp
+}
+
+func (g GoalUpdateEvent) IsHistory() bool {
+	return g.isHistory
+}
+
+// SubscribeKind tells what kind of subscription a SubscribeEvent is about.
+type SubscribeKind int
+
+const (
+	// SubscribeNew is a first time subscription.
+	SubscribeNew SubscribeKind = iota
+	// SubscribeRenewal is a resubscription or renewal of a running subscription.
+	SubscribeRenewal
+	// SubscribeGifted is a subscription gifted to the user.
+	SubscribeGifted
+)
+
+func (k SubscribeKind) String() string {
+	switch k {
+	case SubscribeNew:
+		return "new"
+	case SubscribeRenewal:
+		return "renewal"
+	case SubscribeGifted:
+		return "gifted"
+	}
+	return fmt.Sprintf("SubscribeKind(%d)", int(k))
+}
+
+// SubscribeEvent is emitted when a viewer subscribes to the host. Months is the number of months the user has been
+// subscribed, the raw statuses are passed along as sent by TikTok.
+type SubscribeEvent struct {
+	MessageID int64
+	Timestamp int64
+	User      *User
+	Months    int
+	Kind      SubscribeKind
+	Type      pb.SubscribeType
+	OldStatus pb.OldSubscribeStatus
+	Status    pb.SubscribingStatus
+	IsGift    bool
+	eventMeta
+}
+
+func (s SubscribeEvent) CreatedTimestamp() int64 {
+	return s.Timestamp
+}
+
+func (s SubscribeEvent) IsHistory() bool {
+	return s.isHistory
+}
+
 type Battle struct {
 	Host   int64
 	Groups []*BattleGroup
@@ -241,13 +818,74 @@ type BattleGroup struct {
 	Users  []*User
 }
 
+// User is a TikTok user as sent with events. ID is the numeric user ID, Username the handle shown after the @ and
+// SecUID the ID used by the TikTok web API. Nickname is the display name and can be changed at any time, use Key to
+// identify a user. The optional parts are nil if TikTok did not send them, which depends on the event; chat and gift
+// events usually carry the most.
 type User struct {
 	ID              int64
 	Username        string
+	SecUID          string
 	Nickname        string
 	AvatarThumb     *ProfilePicture
 	ExtraAttributes *ExtraAttributes
 	Badge           *BadgeAttributes
+	Verified        bool
+	TopVipNo        int
+	FollowInfo      *FollowInfo
+	PayGrade        *PayGrade
+	FanClub         *FanClub
+	Subscriber      *SubscriberInfo
+}
+
+// Key returns a stable key for the user to de-duplicate and store users by. It is the numeric ID if known, otherwise
+// the SecUID or the handle, and empty if TikTok sent neither.
+func (u *User) Key() string {
+	switch {
+	case u == nil:
+		return ""
+	case u.ID != 0:
+		return strconv.FormatInt(u.ID, 10)
+	case u.SecUID != "":
+		return "sec:" + u.SecUID
+	case u.Username != "":
+		return "@" + u.Username
+	}
+	return ""
+}
+
+// FollowInfo holds the follower counts of a user.
+type FollowInfo struct {
+	Following int
+	Followers int
+}
+
+// PayGrade is the gifter level of a user.
+type PayGrade struct {
+	Level int
+	Name  string
+	Icon  *ProfilePicture
+	Score int
+}
+
+// FanClub is the membership of a user in the fan club of the host.
+type FanClub struct {
+	Name   string
+	Level  int
+	Score  int
+	Active bool
+	Badge  *ProfilePicture
+}
+
+// SubscriberInfo is the subscription of a user. TikTok does not send the subscription tier, Badge is the badge image
+// of it.
+type SubscriberInfo struct {
+	IsSubscriber       bool
+	SubscribedToHost   bool
+	InGracePeriod      bool
+	Badge              *ProfilePicture
+	SubscriberCount    int
+	EnableSubscription bool
 }
 
 type UserIdentity struct {
@@ -271,9 +909,13 @@ type BadgeAttributes struct {
 	Badges []*UserBadge
 }
 
+// UserBadge is a badge shown next to the name of a user. Type is the display type of the badge, image, text, string
+// or combine, which decides whether Image, Text or both are set. Name is the raw badge and kept for compatibility.
 type UserBadge struct {
-	Type string
-	Name string
+	Type  string
+	Name  string
+	Text  string
+	Image *ProfilePicture
 }
 
 type roomInfoRsp struct {
@@ -1085,10 +1727,13 @@ type SignedURL struct {
 }
 
 // DisconnectEvent sent went disconnected from live. When this event occurs no other events will be emitted and the live
-// instance should be closed with `Closed`. A new track user/room should be invoked to reconnect if desired. This event
-// should always be emitted.
+// instance should be closed with `Closed`. Unless reconnecting is disabled, this is only sent once all reconnect attempts
+// have failed or the live has ended. A new track user/room should be invoked to reconnect if desired. This event is
+// always emitted, but like any other event it follows the BackpressurePolicy: DropOldest and DropNewest can drop it when
+// Events is full, Block and SpillToDisk deliver it unless the live is closed first. The Events channel being closed
+// is the reliable end of the live.
 type DisconnectEvent struct {
-	created time.Time
+	eventMeta
 }
 
 func (d DisconnectEvent) IsHistory() bool {
@@ -1099,6 +1744,54 @@ func (d DisconnectEvent) CreatedTimestamp() int64 {
 	return d.created.Unix()
 }
 
+// ReconnectingEvent is sent when the websocket dropped and the live is about to try to reconnect after Delay.
+type ReconnectingEvent struct {
+	Attempt int
+	Delay   time.Duration
+	eventMeta
+}
+
+func (r ReconnectingEvent) IsHistory() bool {
+	return false
+}
+
+func (r ReconnectingEvent) CreatedTimestamp() int64 {
+	return r.created.Unix()
+}
+
+// ReconnectedEvent is sent when the live resumed after a dropped websocket. Events continue on the same channel.
+type ReconnectedEvent struct {
+	Attempts int
+	Downtime time.Duration
+	eventMeta
+}
+
+func (r ReconnectedEvent) IsHistory() bool {
+	return false
+}
+
+func (r ReconnectedEvent) CreatedTimestamp() int64 {
+	return r.created.Unix()
+}
+
+// DroppedEventsEvent is sent on Live.Events once there is room again after events had to be dropped because the
+// channel was full. Dropped and DroppedGifts count the events dropped since the previous DroppedEventsEvent.
+type DroppedEventsEvent struct {
+	Dropped      int
+	DroppedGifts int
+	Total        uint64
+	Policy       BackpressurePolicy
+	eventMeta
+}
+
+func (d DroppedEventsEvent) IsHistory() bool {
+	return false
+}
+
+func (d DroppedEventsEvent) CreatedTimestamp() int64 {
+	return d.created.Unix()
+}
+
 type LimitInfo struct {
 	Max       int       `json:"max"`
 	Remaining int       `json:"remaining"`
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"math/rand"
+	"strconv"
 	"time"
 
 	"github.com/erni27/imcache"
@@ -54,7 +55,7 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 			Timestamp: pt.Common.CreateTime,
 			Type:      pt.Common.Method,
 			Message:   pt.Content,
-			isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 	case *pb.WebcastRoomPinMessage:
 		{
@@ -67,7 +68,7 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 					Timestamp: pt.Common.CreateTime,
 					Type:      pt.OriginalMsgType,
 					Message:   "<unknown>",
-					isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+					eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 				}, nil
 			}
 			m := tReflect.New().Interface()
@@ -81,12 +82,16 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 					Timestamp: pt.Common.CreateTime,
 					Type:      pt.OriginalMsgType,
 					Message:   "<unknown>",
-					isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+					eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 				}, nil
 			}
 
 			typeStr := pt.OriginalMsgType
 			msgPinned := "<unknown pinned type>"
+			text := toDisplayText(pt.Common.DisplayText)
+			if text.Text != "" {
+				msgPinned = text.Text
+			}
 			switch pt2 := m.(type) {
 			// Todo make a pin return type
 			case *pb.WebcastChatMessage:
@@ -95,7 +100,7 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 					Timestamp: pt.Common.CreateTime,
 					Comment:   "<pinned>: " + pt2.Content,
 					User:      toUser(pt2.User),
-					isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+					eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 				}, nil
 			default:
 				base := base64.RawStdEncoding.EncodeToString(pt.PinnedMessage)
@@ -105,11 +110,12 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 
 			}
 			return RoomEvent{
-				MessageID: pt.Common.MsgId,
-				Timestamp: pt.Common.CreateTime,
-				Type:      typeStr,
-				Message:   msgPinned,
-				isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+				MessageID:   pt.Common.MsgId,
+				Timestamp:   pt.Common.CreateTime,
+				Type:        typeStr,
+				Message:     msgPinned,
+				DisplayText: text,
+				eventMeta:   eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 			}, nil
 		}
 	case *pb.WebcastChatMessage:
@@ -119,7 +125,7 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 			User:         toUser(pt.User),
 			UserIdentity: toUserIdentity(pt.UserIdentity),
 			Timestamp:    pt.Common.CreateTime,
-			isHistory:    msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			eventMeta:    eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 	case *pb.WebcastMemberMessage:
 		return UserEvent{
@@ -127,39 +133,43 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 			Timestamp: pt.Common.CreateTime,
 			Event:     toUserType(pt.Action.String()),
 			User:      toUser(pt.User),
-			isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 	case *pb.WebcastLiveGameIntroMessage:
+		text := toDisplayText(pt.GameText)
 		return RoomEvent{
-			MessageID: pt.Common.MsgId,
-			Timestamp: pt.Common.CreateTime,
-			Type:      pt.Common.Method,
-			Message:   pt.GameText.DefaultPattern,
-			isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			MessageID:   pt.Common.MsgId,
+			Timestamp:   pt.Common.CreateTime,
+			Type:        pt.Common.Method,
+			Message:     text.Text,
+			DisplayText: text,
+			eventMeta:   eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 	case *pb.WebcastRoomMessage:
+		text := toDisplayText(pt.Common.DisplayText)
 		return RoomEvent{
-			MessageID: pt.Common.MsgId,
-			Timestamp: pt.Common.CreateTime,
-			Type:      pt.Common.Method,
-			// TODO: Make this actually use pieces list and fill out the format text correctly.
-			Message:   pt.Common.DisplayText.DefaultPattern,
-			isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			MessageID:   pt.Common.MsgId,
+			Timestamp:   pt.Common.CreateTime,
+			Type:        pt.Common.Method,
+			Message:     text.Text,
+			DisplayText: text,
+			eventMeta:   eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 	case *pb.WebcastRoomUserSeqMessage:
 		return ViewersEvent{
 			MessageID: pt.Common.MsgId,
 			Timestamp: pt.Common.CreateTime,
 			Viewers:   int(pt.Total),
-			isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 	case *pb.WebcastSocialMessage:
 		return UserEvent{
-			MessageID: pt.Common.MsgId,
-			Timestamp: pt.Common.CreateTime,
-			Event:     toUserType(pt.Common.DisplayText.Key),
-			User:      toUser(pt.User),
-			isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			MessageID:   pt.Common.MsgId,
+			Timestamp:   pt.Common.CreateTime,
+			Event:       toUserType(pt.Common.DisplayText.Key),
+			User:        toUser(pt.User),
+			DisplayText: toDisplayText(pt.Common.DisplayText),
+			eventMeta:   eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 	case *pb.WebcastGiftMessage:
 		if pt.GiftId == 0 && pt.User == nil {
@@ -171,19 +181,22 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 			Timestamp:    pt.Common.CreateTime,
 			ID:           pt.GiftId,
 			GroupID:      pt.GroupId,
-			Name:         pt.Gift.Name,
-			Describe:     pt.Gift.Describe,
-			Diamonds:     int(pt.Gift.DiamondCount),
+			Name:         pt.Gift.GetName(),
+			Describe:     pt.Gift.GetDescribe(),
+			Diamonds:     int(pt.Gift.GetDiamondCount()),
+			Icon:         toProfilePicture(pt.Gift.GetIcon()),
+			Image:        toProfilePicture(pt.Gift.GetImage()),
 			RepeatCount:  int(pt.RepeatCount),
 			RepeatEnd:    pt.RepeatEnd == 1,
-			Type:         int(pt.Gift.Type),
+			Type:         int(pt.Gift.GetType()),
 			ToUserID:     int64(pt.UserGiftReciever.UserId),
 			User:         toUser(pt.User),
 			UserIdentity: toUserIdentity(pt.UserIdentity),
-			isHistory:    msg.IsHistory || cachedHistory(pt.Common.MsgId),
-			IsComboGift:  pt.GroupId != 0,
+			eventMeta:    eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+			IsComboGift:  pt.GroupId != 0 || pt.Gift.GetCombo(),
 		}, nil
 	case *pb.WebcastLikeMessage:
+		text := toDisplayText(pt.Common.DisplayText)
 		return LikeEvent{
 			MessageID:   pt.Common.MsgId,
 			Timestamp:   pt.Common.CreateTime,
@@ -191,8 +204,9 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 			TotalLikes:  int(pt.Total),
 			User:        toUser(pt.User),
 			DisplayType: pt.Common.Method,
-			Label:       pt.Common.DisplayText.String(),
-			isHistory:   msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			Label:       text.Text,
+			DisplayText: text,
+			eventMeta:   eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 
 	case *pb.WebcastQuestionNewMessage:
@@ -201,7 +215,7 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 			Timestamp: pt.Common.CreateTime,
 			Quesion:   pt.Details.Text,
 			User:      toUser(pt.Details.User),
-			isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 
 	case *pb.WebcastControlMessage:
@@ -210,7 +224,7 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 			Timestamp:   pt.Common.CreateTime,
 			Action:      int(pt.Action),
 			Description: pt.Action.String(),
-			isHistory:   msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			eventMeta:   eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 
 	case *pb.WebcastLinkMicBattle:
@@ -219,19 +233,7 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 			groups := u.HostGroup
 			for _, group := range groups {
 				for _, user := range group.Host {
-					// urls := make([]string, 5)
-					// for _, img := range user.Images {
-					// 	urls = append(urls, img.UrlList...)
-					// }
-					users = append(users, &User{
-						ID:       int64(user.Id),
-						Username: user.ProfileId,
-						Nickname: user.Name,
-						// ProfilePicture: &ProfilePicture{
-						// 	Urls: urls,
-						// },
-					})
-
+					users = append(users, battleUser(user.Id, "", user.ProfileId, user.Name, user.Images))
 				}
 
 			}
@@ -239,8 +241,11 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 		return MicBattleEvent{
 			MessageID: pt.Common.MsgId,
 			Timestamp: pt.Common.CreateTime,
+			BattleID:  int64(pt.Id),
+			Status:    pt.BattleStatus,
 			Users:     users,
-			isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			Teams:     toBattleTeams(pt),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 
 	case *pb.WebcastLinkMicArmies:
@@ -265,9 +270,62 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 		return BattlesEvent{
 			MessageID: pt.Common.MsgId,
 			Timestamp: pt.Common.CreateTime,
+			BattleID:  int64(pt.Id),
 			Status:    int(pt.BattleStatus),
 			Battles:   battles,
-			isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}, nil
+	case *pb.WebcastLinkMicBattlePunishFinish:
+		return BattlePunishFinishEvent{
+			MessageID: pt.Header.MsgId,
+			Timestamp: pt.Header.CreateTime,
+			BattleID:  int64(pt.Id2),
+			ChannelID: int64(pt.Id1),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Header.MsgId)},
+		}, nil
+	case *pb.WebcastLinkmicBattleTaskMessage:
+		return BattleTaskEvent{
+			MessageID: pt.Header.MsgId,
+			Timestamp: pt.Header.CreateTime,
+			BattleID:  battleTaskID(pt.ProtoReflect().GetUnknown()),
+			Type:      int(pt.Data2),
+			Value:     int(pt.Data3.GetData1().GetData1()),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Header.MsgId)},
+		}, nil
+	case *pb.WebcastLinkMicMethod:
+		return LinkMicMethodEvent{
+			MessageID:      pt.Common.MsgId,
+			Timestamp:      pt.Common.CreateTime,
+			Type:           pt.MessageType,
+			UserID:         pt.UserId,
+			InviterID:      pt.InviteUid,
+			ChannelID:      pt.ChannelId,
+			LinkMicID:      pt.AnchorLinkmicId,
+			FanTicket:      int(pt.FanTicket),
+			TotalFanTicket: int(pt.TotalLinkMicFanTicket),
+			eventMeta:      eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}, nil
+	case *pb.WebcastLinkMicFanTicketMethod:
+		notice := pt.FanTicketRoomNotice
+		if notice == nil {
+			return nil, nil
+		}
+		users := make([]FanTicket, 0, len(notice.UserFanTicketList))
+		for _, u := range notice.UserFanTicketList {
+			users = append(users, FanTicket{
+				UserID:    u.UserId,
+				FanTicket: int(u.FanTicket),
+				Score:     int(u.MatchTotalScore),
+				Rank:      int(u.MatchRank),
+			})
+		}
+		return LinkMicFanTicketEvent{
+			MessageID: pt.Common.MsgId,
+			Timestamp: pt.Common.CreateTime,
+			MatchID:   notice.MatchId,
+			Total:     int(notice.TotalLinkMicFanTicket),
+			Users:     users,
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 	case *pb.WebcastLiveIntroMessage:
 		return IntroEvent{
@@ -276,9 +334,246 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 			ID:        int(pt.RoomId),
 			Title:     pt.Content,
 			User:      toUser(pt.Host),
-			isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
 		}, nil
 
+	case *pb.WebcastEnvelopeMessage:
+		info := pt.EnvelopeInfo
+		if info == nil {
+			return nil, nil
+		}
+		created := toEnvelopeTime(info.CreateAt)
+		if created.IsZero() && pt.Common.CreateTime != 0 {
+			created = time.UnixMilli(pt.Common.CreateTime)
+		}
+		return EnvelopeEvent{
+			MessageID:        pt.Common.MsgId,
+			Timestamp:        pt.Common.CreateTime,
+			EnvelopeID:       info.EnvelopeId,
+			BusinessType:     info.BusinessType,
+			Display:          pt.Display,
+			FollowShowStatus: info.FollowShowStatus,
+			// The envelope only carries a part of the sender, converted like other users so User.Key matches.
+			Sender: toUser(&pb.User{
+				IdStr:       info.SendUserId,
+				Nickname:    info.SendUserName,
+				AvatarThumb: info.SendUserAvatar,
+			}),
+			Diamonds:  int(info.DiamondCount),
+			People:    int(info.PeopleCount),
+			CreatedAt: created,
+			OpenAt:    envelopeOpenAt(created, int64(info.UnpackAt)),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}, nil
+	case *pb.WebcastCaptionMessage:
+		if pt.CaptionData == nil {
+			return nil, nil
+		}
+		at := toEpochTime(int64(pt.TimeStamp))
+		if at.IsZero() && pt.Common.CreateTime != 0 {
+			at = time.UnixMilli(pt.Common.CreateTime)
+		}
+		return CaptionEvent{
+			MessageID: pt.Common.MsgId,
+			Timestamp: pt.Common.CreateTime,
+			Time:      at,
+			Language:  pt.CaptionData.Language,
+			Text:      pt.CaptionData.Text,
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}, nil
+	case *pb.WebcastEmoteChatMessage:
+		emotes := make([]Emote, 0, len(pt.EmoteList))
+		for _, e := range pt.EmoteList {
+			emotes = append(emotes, Emote{
+				ID:    e.EmoteId,
+				Image: toProfilePicture(e.Image),
+				Type:  e.EmoteType,
+			})
+		}
+		return EmoteChatEvent{
+			MessageID:    pt.Common.MsgId,
+			Timestamp:    pt.Common.CreateTime,
+			User:         toUser(pt.User),
+			UserIdentity: toUserIdentity(pt.UserIdentity),
+			Emotes:       emotes,
+			eventMeta:    eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}, nil
+	case *pb.WebcastBarrageMessage:
+		barrage := BarrageEvent{
+			MessageID: pt.Common.MsgId,
+			Timestamp: pt.Common.CreateTime,
+			Type:      pt.MsgType,
+			EventName: pt.Event.GetEventName(),
+			Content:   pt.Content.GetDefaultPattern(),
+			Icon:      toProfilePicture(pt.Icon),
+			Duration:  int(pt.Duration),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}
+		switch {
+		case pt.UserGradeParam != nil:
+			barrage.User = toUser(pt.UserGradeParam.User)
+			barrage.Level = int(pt.UserGradeParam.CurrentGrade)
+		case pt.FansLevelParam != nil:
+			barrage.User = toUser(pt.FansLevelParam.User)
+			barrage.Level = int(pt.FansLevelParam.CurrentGrade)
+		}
+		if pt.SubscribeGiftParam != nil {
+			barrage.GiftSubCount = int(pt.SubscribeGiftParam.GiftSubCount)
+		}
+		return barrage, nil
+	case *pb.WebcastRankUpdateMessage:
+		update := RankUpdateEvent{
+			MessageID: pt.Common.MsgId,
+			Timestamp: pt.Common.CreateTime,
+			GroupType: pt.GroupType,
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}
+		for _, u := range pt.UpdatesList {
+			update.Updates = append(update.Updates, RankUpdate{
+				RankType:  u.RankType,
+				Rank:      int(u.OwnerRank),
+				OnRank:    u.Owneronrank,
+				Text:      u.DefaultContent.GetDefaultPattern(),
+				Countdown: u.Countdown,
+			})
+		}
+		for _, tab := range pt.TabsList {
+			update.Tabs = append(update.Tabs, RankTab{
+				RankType: tab.RankType,
+				Title:    tab.Title,
+			})
+		}
+		return update, nil
+	case *pb.WebcastRankTextMessage:
+		return RankTextEvent{
+			MessageID:  pt.Common.MsgId,
+			Timestamp:  pt.Common.CreateTime,
+			Scene:      int(pt.Scene),
+			UserID:     pt.CurUserId,
+			RankBefore: int(pt.OwnerIdxBeforeUpdate),
+			Rank:       int(pt.OwnerIdxAfterUpdate),
+			Text:       pt.OtherGetBadgeMsg.GetDefaultPattern(),
+			SelfText:   pt.SelfGetBadgeMsg.GetDefaultPattern(),
+			eventMeta:  eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}, nil
+	case *pb.WebcastHourlyRankMessage:
+		var rankings []Ranking
+		if d := pt.Data; d != nil {
+			for _, r := range []*pb.Ranking{d.Rankingdata.GetRankdata(), d.Rankings, d.Rankingdata2.GetRankdata()} {
+				if r != nil {
+					rankings = append(rankings, toRanking(r))
+				}
+			}
+		}
+		return HourlyRankEvent{
+			MessageID: pt.Common.MsgId,
+			Timestamp: pt.Common.CreateTime,
+			Rankings:  rankings,
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}, nil
+	case *pb.WebcastOecLiveShoppingMessage:
+		shopping := ShoppingEvent{
+			MessageID: pt.Common.MsgId,
+			Timestamp: pt.Common.CreateTime,
+			Action:    ShoppingUnpin,
+			Type:      int(pt.Data1),
+			ShopID:    pt.Details.GetId1(),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}
+		if d := pt.ShopData; d != nil && d.Title != "" {
+			shopping.Action = ShoppingPin
+			shopping.ShopName = d.ShopName
+			shopping.ShopURL = d.ShopUrl
+			shopping.Title = d.Title
+			shopping.Price = d.PriceString
+			shopping.ImageURL = d.ImageUrl
+		}
+		if t := pt.ShopTimings; t != nil {
+			shopping.StartTime = int64(t.Timestamp1)
+			shopping.EndTime = int64(t.Timestamp2)
+		}
+		return shopping, nil
+	case *pb.WebcastImDeleteMessage:
+		return MessageDeletedEvent{
+			MessageID:  pt.Common.MsgId,
+			Timestamp:  pt.Common.CreateTime,
+			MessageIDs: pt.DeleteMsgIdsList,
+			UserIDs:    pt.DeleteUserIdsList,
+			eventMeta:  eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}, nil
+	case *pb.WebcastGoalUpdateMessage:
+		return GoalUpdateEvent{
+			MessageID:         pt.Common.MsgId,
+			Timestamp:         pt.Common.CreateTime,
+			Goal:              toGoal(pt.Goal),
+			ContributorID:     pt.ContributorId,
+			ContributorName:   pt.ContributorDisplayId,
+			ContributorAvatar: toProfilePicture(pt.ContributorAvatar),
+			ContributeCount:   int(pt.ContributeCount),
+			ContributeScore:   int(pt.ContributeScore),
+			Pinned:            pt.Pin,
+			Unpinned:          pt.Unpin,
+			eventMeta:         eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}, nil
+	case *pb.WebcastSubNotifyMessage:
+		kind := SubscribeNew
+		switch {
+		case pt.IsSend:
+			kind = SubscribeGifted
+		case pt.OldSubscribeStatus != pb.OldSubscribeStatus_OLDSUBSCRIBESTATUS_FIRST &&
+			pt.OldSubscribeStatus != pb.OldSubscribeStatus_OLDSUBSCRIBESTATUS_DEFAULT,
+			pt.SubMonth > 1:
+			kind = SubscribeRenewal
+		}
+		return SubscribeEvent{
+			MessageID: pt.Common.MsgId,
+			Timestamp: pt.Common.CreateTime,
+			User:      toUser(pt.User),
+			Months:    int(pt.SubMonth),
+			Kind:      kind,
+			Type:      pt.SubscribeType,
+			OldStatus: pt.OldSubscribeStatus,
+			Status:    pt.SubscribingStatus,
+			IsGift:    pt.IsSend,
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Common.MsgId)},
+		}, nil
+	case *pb.WebcastPollMessage:
+		isHistory := msg.IsHistory || cachedHistory(pt.Common.MsgId)
+		switch {
+		case pt.StartContent != nil:
+			return PollStartEvent{
+				MessageID: pt.Common.MsgId,
+				Timestamp: pt.Common.CreateTime,
+				PollID:    pt.PollId,
+				Title:     pt.StartContent.Title,
+				Options:   toPollOptions(pt.StartContent.OptionList),
+				StartTime: pt.StartContent.StartTime,
+				EndTime:   pt.StartContent.EndTime,
+				Operator:  toUser(pt.StartContent.Operator),
+				eventMeta: eventMeta{isHistory: isHistory},
+			}, nil
+		case pt.EndContent != nil:
+			return PollEndEvent{
+				MessageID: pt.Common.MsgId,
+				Timestamp: pt.Common.CreateTime,
+				PollID:    pt.PollId,
+				Options:   toPollOptions(pt.EndContent.OptionList),
+				EndType:   int(pt.EndContent.EndType),
+				Operator:  toUser(pt.EndContent.Operator),
+				eventMeta: eventMeta{isHistory: isHistory},
+			}, nil
+		case pt.UpdateContent != nil:
+			return PollUpdateEvent{
+				MessageID: pt.Common.MsgId,
+				Timestamp: pt.Common.CreateTime,
+				PollID:    pt.PollId,
+				Options:   toPollOptions(pt.UpdateContent.OptionList),
+				eventMeta: eventMeta{isHistory: isHistory},
+			}, nil
+		}
+		debugHandler(fmt.Sprintf("poll message %d without content", pt.PollId))
+		return nil, nil
+
 	case *pb.WebcastInRoomBannerMessage:
 		var data interface{}
 		// TODO: should we make a type for this instead of unmarshalling to see it is an error then feeding it up?
@@ -291,7 +586,7 @@ func parseMsg(msg *pb.WebcastResponse_Message, warnHandler func(...interface{}),
 			MessageID: pt.Header.MsgId,
 			Timestamp: pt.Header.CreateTime,
 			Data:      data,
-			isHistory: msg.IsHistory || cachedHistory(pt.Header.MsgId),
+			eventMeta: eventMeta{isHistory: msg.IsHistory || cachedHistory(pt.Header.MsgId)},
 		}, nil
 
 	default:
@@ -312,6 +607,71 @@ func toProfilePicture(pic *pb.Image) *ProfilePicture {
 	return nil
 }
 
+func toRanking(r *pb.Ranking) Ranking {
+	ranking := Ranking{
+		Type:  r.Type,
+		Label: r.Label,
+	}
+	for _, d := range r.Details {
+		if d.Label != "" {
+			ranking.Details = append(ranking.Details, d.Label)
+		}
+	}
+	return ranking
+}
+
+// toEnvelopeTime parses the creation time of an envelope, sent as a string of epoch seconds or milliseconds.
+func toEnvelopeTime(s string) time.Time {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return toEpochTime(v)
+}
+
+// toEpochTime converts a timestamp in epoch seconds or milliseconds, TikTok uses both, to a time.
+func toEpochTime(v int64) time.Time {
+	switch {
+	case v <= 0:
+		return time.Time{}
+	case v > 1e12:
+		return time.UnixMilli(v)
+	}
+	return time.Unix(v, 0)
+}
+
+// envelopeOpenAt computes when an envelope can be opened. The unpack time is sent as epoch seconds, older versions
+// send the number of seconds after creation instead.
+func envelopeOpenAt(created time.Time, unpackAt int64) time.Time {
+	switch {
+	case unpackAt > 1e9:
+		return time.Unix(unpackAt, 0)
+	case unpackAt > 0 && !created.IsZero():
+		return created.Add(time.Duration(unpackAt) * time.Second)
+	}
+	return time.Time{}
+}
+
+func toPollOptions(list []*pb.PollOptionInfo) []PollOption {
+	options := make([]PollOption, 0, len(list))
+	for _, o := range list {
+		option := PollOption{
+			Index: int(o.OptionIdx),
+			Text:  o.DisplayContent,
+			Votes: int(o.Votes),
+		}
+		for _, v := range o.VoteUserList {
+			option.Voters = append(option.Voters, toUser(&pb.User{
+				Id:          v.UserId,
+				Nickname:    v.NickName,
+				AvatarThumb: v.AvatarThumb,
+			}))
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
 func cachedHistory(id int64) bool {
 	_, present := msgIDCache.GetOrSet(id, struct{}{}, imcache.WithExpiration(messageHistoryTimeout))
 	return present
@@ -325,18 +685,35 @@ func routineErrHandler(err ...interface{}) {
 	slog.Debug(fmt.Sprint(err...), "logger", "gotiktoklive-default")
 }
 
+// battleUser converts a host or viewer of the link mic battle messages, which only carry a part of the user, the same
+// way as toUser so User.Key matches the user of other events. The profile ID is the display ID.
+func battleUser(id uint64, idStr, profileID, name string, images []*pb.Image) *User {
+	u := &pb.User{
+		Id:        int64(id),
+		IdStr:     idStr,
+		DisplayId: profileID,
+		Nickname:  name,
+	}
+	if len(images) > 0 {
+		u.AvatarThumb = images[0]
+	}
+	return toUser(u)
+}
+
 func toUser(u *pb.User) *User {
 	if u == nil {
 		return &User{}
 	}
-	username := u.IdStr
-	if u.IdStr == "" {
-		username = u.Nickname
+	id := u.Id
+	if id == 0 {
+		// Some events only carry the ID as a string.
+		id, _ = strconv.ParseInt(u.IdStr, 10, 64)
 	}
 
 	user := User{
-		ID:          int64(u.Id),
-		Username:    username,
+		ID:          id,
+		Username:    u.DisplayId,
+		SecUID:      u.SecUid,
 		Nickname:    u.Nickname,
 		AvatarThumb: toProfilePicture(u.AvatarThumb),
 	}
@@ -348,18 +725,87 @@ func toUser(u *pb.User) *User {
 	if u.BadgeList != nil {
 		var badges []*UserBadge
 		for _, badge := range u.BadgeList {
-			badges = append(badges, &UserBadge{
-				Type: badge.DisplayType.String(),
-				Name: badge.String(),
-			})
+			badges = append(badges, toUserBadge(badge))
 		}
 		user.Badge = &BadgeAttributes{
 			Badges: badges,
 		}
 	}
+
+	user.Verified = u.Verified
+	user.TopVipNo = int(u.TopVipNo)
+	if f := u.FollowInfo; f != nil {
+		user.FollowInfo = &FollowInfo{
+			Following: int(f.FollowingCount),
+			Followers: int(f.FollowerCount),
+		}
+	}
+	if g := u.PayGrade; g != nil {
+		user.PayGrade = &PayGrade{
+			Level: int(g.Level),
+			Name:  g.Name,
+			Icon:  toProfilePicture(g.Icon),
+			Score: int(g.Score),
+		}
+	}
+	user.FanClub = toFanClub(u.FansClub, u.FansClubInfo)
+	if sub := u.SubscribeInfo; sub != nil {
+		user.Subscriber = &SubscriberInfo{
+			IsSubscriber:       sub.IsSubscribe,
+			SubscribedToHost:   sub.IsSubscribedToAnchor,
+			InGracePeriod:      sub.IsInGracePeriod,
+			SubscriberCount:    int(sub.SubscriberCount),
+			EnableSubscription: sub.EnableSubscription,
+		}
+		if sub.Badge != nil {
+			user.Subscriber.Badge = toProfilePicture(sub.Badge.OriginImg)
+		}
+	}
 	return &user
 }
 
+func toUserBadge(b *pb.BadgeStruct) *UserBadge {
+	badge := &UserBadge{
+		Type: b.DisplayType.String(),
+		Name: b.String(),
+	}
+	switch v := b.BadgeType.(type) {
+	case *pb.BadgeStruct_Image:
+		badge.Image = toProfilePicture(v.Image.GetImage())
+	case *pb.BadgeStruct_Text:
+		badge.Text = v.Text.GetDefaultPattern()
+	case *pb.BadgeStruct_Str:
+		badge.Text = v.Str.GetStr()
+	case *pb.BadgeStruct_Combine:
+		badge.Image = toProfilePicture(v.Combine.GetIcon())
+		badge.Text = v.Combine.GetStr()
+		if badge.Text == "" {
+			badge.Text = v.Combine.GetText().GetDefaultPattern()
+		}
+	}
+	return badge
+}
+
+// toFanClub merges the fan club data and info TikTok sends separately. It returns nil if the user is not in the fan
+// club.
+func toFanClub(club *pb.User_FansClub, info *pb.User_FansClubInfo) *FanClub {
+	data := club.GetData()
+	if data.GetClubName() == "" && data.GetLevel() == 0 && info.GetFansLevel() == 0 {
+		return nil
+	}
+	fanClub := &FanClub{
+		Name:   data.GetClubName(),
+		Level:  int(data.GetLevel()),
+		Score:  int(info.GetFansScore()),
+		Active: data.GetUserFansClubStatus() == pb.User_FansClub_FansClubData_ACTIVE || (data == nil && !info.GetIsSleeping()),
+		Badge:  toProfilePicture(info.GetBadge()),
+	}
+	if fanClub.Level == 0 {
+		fanClub.Level = int(info.GetFansLevel())
+	}
+	return fanClub
+}
+
 func toUserIdentity(uid *pb.UserIdentity) *UserIdentity {
 	if uid == nil {
 		return nil
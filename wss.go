@@ -3,6 +3,7 @@ package gotiktoklive
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -19,7 +20,7 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func (l *Live) connect(addr string, params map[string]string) error {
+func (l *Live) connect(ctx context.Context, addr string, params map[string]string) error {
 	u, err := url.Parse("https://tiktok.com/")
 	if err != nil {
 		return nil
@@ -86,35 +87,80 @@ func (l *Live) connect(addr string, params map[string]string) error {
 	dialer := ws.Dialer{
 		Header: ws.HandshakeHeaderHTTP(headers),
 		NetDial: func(ctx context.Context, a, b string) (net.Conn, error) {
+			if d, ok := proxyNetDial.(proxy.ContextDialer); ok {
+				return d.DialContext(ctx, a, b)
+			}
 			return proxyNetDial.Dial(a, b)
 		},
 		// NetDial:   proxy.Dial,
 		Protocols: []string{"echo-protocol"},
 	}
-	conn, _, _, err := dialer.Dial(context.Background(), wsURL)
+	conn, _, _, err := dialer.Dial(ctx, wsURL)
 	if err != nil {
 		return fmt.Errorf("Failed to connect: %w", err)
 	}
+	l.wssMu.Lock()
+	defer l.wssMu.Unlock()
+	// Close may have run while dialing, closeConn would never see this connection.
+	if err := l.ctx.Err(); err != nil {
+		conn.Close()
+		return fmt.Errorf("Failed to connect: %w", err)
+	}
 	l.wss = conn
 	return nil
 }
 
+// conn returns the current websocket connection, which changes on reconnect.
+func (l *Live) conn() net.Conn {
+	l.wssMu.Lock()
+	defer l.wssMu.Unlock()
+	return l.wss
+}
+
+// closeConn closes the current websocket connection, if any.
+func (l *Live) closeConn() {
+	l.wssMu.Lock()
+	defer l.wssMu.Unlock()
+	if l.wss != nil {
+		l.wss.Close()
+	}
+}
+
+// dropConn closes conn and forgets it if it is still the current connection.
+func (l *Live) dropConn(conn net.Conn) {
+	l.wssMu.Lock()
+	defer l.wssMu.Unlock()
+	conn.Close()
+	if l.wss == conn {
+		l.wss = nil
+	}
+}
+
+// writeConn writes a binary client frame to the current websocket connection.
+func (l *Live) writeConn(b []byte) error {
+	l.wssMu.Lock()
+	defer l.wssMu.Unlock()
+	if l.wss == nil {
+		return errNotConnected
+	}
+	return wsutil.WriteClientBinary(l.wss, b)
+}
+
+// readSocket reads and parses messages from the current websocket connection
+// until it drops or the live is closed.
 func (l *Live) readSocket() {
-	defer l.wss.Close()
-	defer func() {
-		select {
-		case <-time.After(5 * time.Second):
-		case l.Events <- &DisconnectEvent{created: time.Now()}:
-		}
-	}()
-	defer l.cancel()
+	conn := l.conn()
+	if conn == nil {
+		return
+	}
+	defer l.dropConn(conn)
 
 	want := ws.OpBinary
 	s := ws.StateClientSide
 
-	controlHandler := wsutil.ControlFrameHandler(l.wss, s)
+	controlHandler := wsutil.ControlFrameHandler(conn, s)
 	rd := wsutil.Reader{
-		Source:          l.wss,
+		Source:          conn,
 		State:           s,
 		CheckUTF8:       true,
 		SkipHeaderCheck: false,
@@ -124,8 +170,12 @@ func (l *Live) readSocket() {
 	for {
 		hdr, err := rd.NextFrame()
 		if err != nil {
-			l.t.errHandler(fmt.Errorf("failed to read websocket from server: %w", err))
-			l.wss.Close()
+			select {
+			case <-l.done():
+				// Closed on purpose, nothing to report.
+			default:
+				l.t.errHandler(fmt.Errorf("failed to read websocket from server: %w", err))
+			}
 			return
 		}
 		// If msg is ping or close
@@ -183,6 +233,98 @@ func (l *Live) readSocket() {
 	}
 }
 
+// run keeps reading from the websocket for the lifetime of the live. When the
+// connection drops and reconnecting is enabled the room data is fetched again
+// and the websocket redialed, keeping the Events channel open.
+func (l *Live) run() {
+	// The last events are emitted before the context is canceled.
+	defer l.cancel()
+	defer l.finish()
+
+	for {
+		l.readSocket()
+
+		select {
+		case <-l.done():
+			return
+		case <-l.t.done():
+			return
+		default:
+		}
+		if !l.t.shouldReconnect || !l.reconnect() {
+			return
+		}
+	}
+}
+
+// finish reports the end of the live once no more messages will be read.
+func (l *Live) finish() {
+	l.endGiftStreaks()
+	l.waitSpillDrained()
+	l.emit(&DisconnectEvent{eventMeta: eventMeta{created: time.Now()}})
+	// The Events channel is closed next, a spilled DisconnectEvent has to be delivered first.
+	l.waitSpillDrained()
+}
+
+// reconnect retries fetching the room data and dialing the websocket with an
+// exponential backoff. It returns false if the live should not be resumed.
+func (l *Live) reconnect() bool {
+	dropped := time.Now()
+	delay := l.t.reconnectMinBackoff
+	for attempt := 1; ; attempt++ {
+		if l.t.reconnectMaxAttempts > 0 && attempt > l.t.reconnectMaxAttempts {
+			l.t.errHandler(fmt.Errorf("giving up reconnecting to room %s after %d attempts", l.ID, attempt-1))
+			return false
+		}
+		l.emit(ReconnectingEvent{
+			Attempt:   attempt,
+			Delay:     delay,
+			eventMeta: eventMeta{created: time.Now()},
+		})
+
+		select {
+		case <-time.After(delay):
+		case <-l.done():
+			return false
+		case <-l.t.done():
+			return false
+		}
+
+		err := l.redial()
+		if errors.Is(err, ErrLiveHasEnded) {
+			l.t.warnHandler(fmt.Sprintf("not reconnecting to room %s: %s", l.ID, err))
+			return false
+		}
+		if err == nil {
+			l.t.infoHandler(fmt.Sprintf("Reconnected to room %s after %d attempts", l.ID, attempt))
+			l.emit(ReconnectedEvent{
+				Attempts:  attempt,
+				Downtime:  time.Since(dropped),
+				eventMeta: eventMeta{created: time.Now()},
+			})
+			return true
+		}
+		l.t.warnHandler(fmt.Errorf("reconnect attempt %d to room %s failed: %w", attempt, l.ID, err))
+
+		delay *= 2
+		if delay > l.t.reconnectMaxBackoff {
+			delay = l.t.reconnectMaxBackoff
+		}
+	}
+}
+
+// redial resumes the room from the saved cursor and internal_ext and opens a
+// new websocket connection.
+func (l *Live) redial() error {
+	if _, err := l.getRoomInfo(l.ctx); err != nil {
+		return err
+	}
+	if err := l.fetchRoomData(l.ctx, true); err != nil {
+		return err
+	}
+	return l.connect(l.ctx, l.wsURL, l.wsParams)
+}
+
 func (l *Live) parseWssMsg(wssMsg []byte) error {
 	var rsp pb.WebcastPushFrame
 	if err := proto.Unmarshal(wssMsg, &rsp); err != nil {
@@ -194,14 +336,20 @@ func (l *Live) parseWssMsg(wssMsg []byte) error {
 		if err := proto.Unmarshal(rsp.Payload, &response); err != nil {
 			return fmt.Errorf("Failed to unmarshal proto WebcastResponse: %w", err)
 		}
-		if response.NeedsAck {
+		if response.NeedsAck && !l.replay {
 			if err := l.sendAck(rsp.LogId, response.InternalExt); err != nil {
 				// Might as well finishing processing all messages, the connection reset will be
 				// caught later
 				l.t.warnHandler("Failed to send websocket ack msg, likely connection reset: %w", err)
 			}
 		}
-		l.cursor = response.Cursor
+		// Keep the last known position to resume from, not every frame carries it.
+		if response.Cursor != "" {
+			l.cursor = response.Cursor
+		}
+		if len(response.InternalExt) > 0 {
+			l.internalExt = string(response.InternalExt)
+		}
 
 		if l.t.Debug {
 			l.t.debugHandler(fmt.Sprintf("Got %d messages, %s", len(response.Messages), response.Cursor))
@@ -213,12 +361,7 @@ func (l *Live) parseWssMsg(wssMsg []byte) error {
 				return fmt.Errorf("Failed to parse response message: %w", err)
 			}
 			if msg != nil {
-				// TODO, let's fix this
-				// If channel is full, discard the first message
-				if len(l.Events) == l.chanSize {
-					<-l.Events
-				}
-				l.Events <- msg
+				l.emit(msg)
 			}
 
 			// If livestream has ended
@@ -254,7 +397,12 @@ func (l *Live) sendPing() {
 		case <-l.t.done():
 			return
 		case <-t.C:
-			if err := wsutil.WriteClientBinary(l.wss, b); err != nil {
+			err := l.writeConn(b)
+			if errors.Is(err, errNotConnected) {
+				// Reconnecting, the next connection gets pinged.
+				continue
+			}
+			if err != nil {
 				l.t.errHandler(fmt.Errorf("Failed to send ping: %w", err))
 			} else {
 				if l.t.enableWSTrace {
@@ -277,7 +425,7 @@ func (l *Live) sendAck(id uint64, extra []byte) error {
 		return err
 	}
 
-	if err := wsutil.WriteClientBinary(l.wss, b); err != nil {
+	if err := l.writeConn(b); err != nil {
 		return err
 	}
 	if l.t.enableWSTrace {
@@ -286,16 +434,16 @@ func (l *Live) sendAck(id uint64, extra []byte) error {
 	return nil
 }
 
-func (l *Live) tryConnectionUpgrade() error {
+func (l *Live) tryConnectionUpgrade(ctx context.Context) error {
 	if l.wsURL == "" {
 		return fmt.Errorf("cannot upgrade connection without a wsURL")
 	}
 	if l.wsParams == nil {
 		return fmt.Errorf("cannot upgrade connection without a wsURL")
 	}
-	err := l.connect(l.wsURL, l.wsParams)
+	err := l.connect(ctx, l.wsURL, l.wsParams)
 	if err != nil {
-		close(l.Events)
+		l.closeEvents()
 		return fmt.Errorf("Connection upgrade failed: %w", err)
 	}
 	if l.t.Debug {
@@ -305,8 +453,9 @@ func (l *Live) tryConnectionUpgrade() error {
 	l.wg.Add(2)
 	go func() {
 		defer l.wg.Done()
-		defer close(l.Events)
-		l.readSocket()
+		defer l.closeEvents()
+		defer l.stopHandlers()
+		l.run()
 	}()
 	go func() {
 		defer l.wg.Done()
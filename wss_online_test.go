@@ -21,7 +21,7 @@ func TestWebsocket(t *testing.T) {
 	tiktok.debugHandler = func(i ...interface{}) {
 		t.Log(i...)
 	}
-	id, err := tiktok.getRoomID(test_types.USERNAME)
+	id, err := tiktok.getRoomID(context.Background(), test_types.USERNAME)
 	if !assert.NoError(t, err) {
 		return
 	}
@@ -40,7 +40,7 @@ func TestWebsocket(t *testing.T) {
 		close(live.Events)
 	}
 
-	err = live.getRoomData()
+	err = live.getRoomData(context.Background())
 	if !assert.NoError(t, err) {
 		return
 	}
@@ -50,7 +50,7 @@ func TestWebsocket(t *testing.T) {
 	}
 	t.Logf("Ws url: %s, %+v", live.wsURL, live.wsParams)
 
-	if err := live.connect(live.wsURL, live.wsParams); err != nil {
+	if err := live.connect(context.Background(), live.wsURL, live.wsParams); err != nil {
 		t.Fatal(err)
 	}
 